@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/kaamebott/pkg/fetcher"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+// collectionQuote mirrors the source fields of model.Quote without the empty ones, as collection files are written.
+type collectionQuote struct {
+	ID        string `json:"id,omitempty"`
+	Value     string `json:"value"`
+	Character string `json:"character,omitempty"`
+	Context   string `json:"context,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Image     string `json:"image,omitempty"`
+}
+
+func fetch(args []string) {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	fs.Usage = flags.Usage(fs)
+
+	output := flags.New("output", "Directory where collection files are written").DocPrefix("fetch").String(fs, "pkg/indexer/indexes", nil)
+	fetcherConfig := fetcher.Flags(fs, "")
+
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if fs.NArg() != 1 {
+		logger.FatalfOnErr(ctx, errors.New("usage: indexer fetch [flags] <universe>"), "fetch")
+	}
+
+	name := fs.Arg(0)
+
+	files, err := fetcher.New(fetcherConfig).Fetch(ctx, name)
+	logger.FatalfOnErr(ctx, err, "fetch")
+
+	for filename, quotes := range files {
+		logger.FatalfOnErr(ctx, writeCollection(filepath.Join(*output, filename), quotes), "write")
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "Collection fetched", slog.String("collection", name), slog.String("file", filename), slog.Int("quotes", len(quotes)))
+	}
+}
+
+func writeCollection(filename string, quotes []model.Quote) (err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close: %w", closeErr))
+		}
+	}()
+
+	output := make([]collectionQuote, len(quotes))
+	for i, quote := range quotes {
+		output[i] = collectionQuote{
+			ID:        quote.ID,
+			Value:     quote.Value,
+			Character: quote.Character,
+			Context:   quote.Context,
+			URL:       quote.URL,
+			Image:     quote.Image,
+		}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(output); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	return nil
+}
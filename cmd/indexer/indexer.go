@@ -10,24 +10,97 @@ import (
 	"github.com/ViBiOh/flags"
 	"github.com/ViBiOh/httputils/v4/pkg/logger"
 	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
 	"github.com/meilisearch/meilisearch-go"
 )
 
 func main() {
+	args := os.Args[1:]
+
+	if len(args) != 0 {
+		switch args[0] {
+		case "lint":
+			os.Exit(lint(args[1:]))
+		case "fetch":
+			fetch(args[1:])
+			return
+		case "index":
+			args = args[1:]
+		}
+	}
+
+	index(args)
+}
+
+func index(args []string) {
 	fs := flag.NewFlagSet("indexer", flag.ExitOnError)
 	fs.Usage = flags.Usage(fs)
 
-	indexName := flags.New("name", "Index Name").DocPrefix("indexer").String(fs, "", nil)
+	indexName := flags.New("name", "Index Name, all universes if blank").DocPrefix("indexer").String(fs, "", nil)
 	searchURL := flags.New("url", "Meilisearch URL").DocPrefix("indexer").String(fs, "http://127.0.0.1:7700", nil)
+	dryRun := flags.New("dryRun", "Print differences with the live index instead of indexing").DocPrefix("indexer").Bool(fs, false, nil)
+	indexerConfig := indexer.Flags(fs, "")
 
-	_ = fs.Parse(os.Args[1:])
+	_ = fs.Parse(args)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
+	indexerService, err := indexer.New(ctx, indexerConfig)
+	logger.FatalfOnErr(ctx, err, "indexer")
+
 	searchClient := meilisearch.New(*searchURL)
 
-	logger.FatalfOnErr(ctx, indexer.Index(ctx, searchClient, *indexName), "index")
+	for _, name := range universeNames(*indexName) {
+		if *dryRun {
+			diff, err := indexerService.Diff(ctx, searchClient, name)
+			logger.FatalfOnErr(ctx, err, "diff")
+
+			printDiff(ctx, name, diff)
+
+			continue
+		}
+
+		logger.FatalfOnErr(ctx, indexerService.Index(ctx, searchClient, name), "index")
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "Collection indexed", slog.String("collection", name))
+	}
+}
+
+func universeNames(name string) []string {
+	if len(name) != 0 {
+		return []string{name}
+	}
+
+	var names []string
+
+	for _, universe := range universe.All() {
+		names = append(names, universe.Name)
+	}
+
+	return names
+}
+
+func printDiff(ctx context.Context, name string, diff indexer.Diff) {
+	for _, change := range []struct {
+		label     string
+		documents []indexer.Document
+	}{
+		{"Added", diff.Added},
+		{"Removed", diff.Removed},
+		{"Changed", diff.Changed},
+	} {
+		for _, document := range change.documents {
+			slog.LogAttrs(ctx, slog.LevelInfo, change.label, slog.String("collection", name), slog.String("id", document.ID), slog.String("character", document.Character), slog.String("value", document.Value))
+		}
+	}
 
-	slog.LogAttrs(ctx, slog.LevelInfo, "Collection indexed", slog.String("collection", *indexName))
+	slog.LogAttrs(ctx, slog.LevelInfo, "Collection compared", slog.String("collection", name),
+		slog.Int("added", len(diff.Added)),
+		slog.Int("removed", len(diff.Removed)),
+		slog.Int("changed", len(diff.Changed)),
+		slog.Int("enrichmentUpdates", diff.EnrichmentUpdates),
+		slog.Int("enrichmentAdds", diff.EnrichmentAdds),
+		slog.Int("enrichmentAmbiguous", diff.EnrichmentAmbiguous),
+	)
 }
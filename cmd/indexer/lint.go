@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+)
+
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = flags.Usage(fs)
+
+	indexName := flags.New("name", "Collection Name, all universes if blank").DocPrefix("lint").String(fs, "", nil)
+	strict := flags.New("strict", "Fail on warnings too").DocPrefix("lint").Bool(fs, false, nil)
+	indexerConfig := indexer.Flags(fs, "")
+
+	_ = fs.Parse(args)
+
+	ctx := context.Background()
+
+	indexerService, err := indexer.New(ctx, indexerConfig)
+	logger.FatalfOnErr(ctx, err, "indexer")
+
+	var errorsCount, warningsCount int
+
+	for _, name := range universeNames(*indexName) {
+		issues, err := indexerService.Lint(ctx, name)
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "lint", slog.String("collection", name), slog.Any("error", err))
+			return 2
+		}
+
+		for _, issue := range issues {
+			level := slog.LevelWarn
+			if issue.Error {
+				level = slog.LevelError
+				errorsCount++
+			} else {
+				warningsCount++
+			}
+
+			slog.LogAttrs(ctx, level, issue.Message, slog.String("file", issue.File), slog.Int("position", issue.Position), slog.String("id", issue.ID))
+		}
+	}
+
+	slog.LogAttrs(ctx, slog.LevelInfo, "Collections linted", slog.Int("errors", errorsCount), slog.Int("warnings", warningsCount))
+
+	if errorsCount != 0 || *strict && warningsCount != 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 	"github.com/ViBiOh/httputils/v4/pkg/server"
 	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/quote"
 	"github.com/ViBiOh/kaamebott/pkg/search"
 )
 
@@ -34,7 +36,9 @@ type configuration struct {
 
 	redis *redis.Config
 
+	indexer *indexer.Config
 	search  *search.Config
+	quote   *quote.Config
 	slack   *slack.Config
 	discord *discord.Config
 }
@@ -57,7 +61,9 @@ func newConfig() configuration {
 
 		redis: redis.Flags(fs, "redis"),
 
+		indexer: indexer.Flags(fs, ""),
 		search:  search.Flags(fs, "search"),
+		quote:   quote.Flags(fs, "quote"),
 		slack:   slack.Flags(fs, "slack"),
 		discord: discord.Flags(fs, "discord"),
 	}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/ViBiOh/httputils/v4/pkg/alcotest"
 	"github.com/ViBiOh/httputils/v4/pkg/health"
@@ -9,6 +10,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 2 {
+		switch os.Args[1] + " " + os.Args[2] {
+		case "discord register":
+			register(os.Args[3:])
+			return
+		case "slack manifest":
+			manifest(os.Args[3:])
+			return
+		}
+	}
+
 	config := newConfig()
 	alcotest.DoAndExit(config.alcotest)
 
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/quote"
+)
+
+func manifest(args []string) {
+	fs := flag.NewFlagSet("kaamebott", flag.ExitOnError)
+	fs.Usage = flags.Usage(fs)
+
+	rendererConfig := renderer.Flags(fs, "", flags.NewOverride("Title", "Kaamebott"), flags.NewOverride("PublicURL", "https://kaamebott.vibioh.fr"))
+	indexerConfig := indexer.Flags(fs, "")
+
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Loading the sources registers their universes, so they get a command too.
+	_, err := indexer.New(ctx, indexerConfig)
+	logger.FatalfOnErr(ctx, err, "indexer")
+
+	logger.FatalfOnErr(ctx, quote.SlackManifest(os.Stdout, rendererConfig.PublicURL, rendererConfig.Title), "manifest")
+}
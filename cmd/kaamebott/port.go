@@ -4,15 +4,57 @@ import (
 	"net/http"
 
 	"github.com/ViBiOh/httputils/v4/pkg/httputils"
+	"github.com/ViBiOh/kaamebott/pkg/quote"
+	"github.com/ViBiOh/kaamebott/pkg/search"
 )
 
+// route is mounted on the port by newPort. Its paths are the ones described by the OpenAPI specification, only the webhooks of the chat platforms have none.
+type route struct {
+	mount   func(*http.ServeMux, services)
+	name    string
+	paths   []string
+	webhook bool
+}
+
+func routes() []route {
+	return []route{
+		{
+			name:    "slack",
+			webhook: true,
+			mount: func(mux *http.ServeMux, services services) {
+				mux.Handle("/slack/", http.StripPrefix("/slack", services.slack.NewServeMux()))
+			},
+		},
+		{
+			name:    "discord",
+			webhook: true,
+			mount: func(mux *http.ServeMux, services services) {
+				mux.Handle("/discord/", http.StripPrefix("/discord", services.discord.NewServeMux()))
+			},
+		},
+		{
+			name:  "api",
+			paths: quote.APIPaths(),
+			mount: func(mux *http.ServeMux, services services) {
+				mux.Handle(quote.APIPath+"/", http.StripPrefix(quote.APIPath, services.quote.NewAPIMux()))
+			},
+		},
+		{
+			name:  "web",
+			paths: []string{search.SearchPattern, search.QuotePattern, search.CardPattern},
+			mount: func(mux *http.ServeMux, services services) {
+				services.renderer.RegisterMux(mux, services.search.TemplateFunc)
+			},
+		},
+	}
+}
+
 func newPort(clients clients, services services) http.Handler {
 	mux := http.NewServeMux()
 
-	mux.Handle("/slack/", http.StripPrefix("/slack", services.slack.NewServeMux()))
-	mux.Handle("/discord/", http.StripPrefix("/discord", services.discord.NewServeMux()))
-
-	services.renderer.RegisterMux(mux, services.search.TemplateFunc)
+	for _, route := range routes() {
+		route.mount(mux, services)
+	}
 
 	return httputils.Handler(mux, clients.health,
 		clients.telemetry.Middleware("http"),
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/quote"
+)
+
+func TestRoutesDescribed(t *testing.T) {
+	t.Parallel()
+
+	paths, ok := quote.OpenAPI("https://kaamebott.vibioh.fr")["paths"].(map[string]any)
+	if !ok {
+		t.Fatal("no paths in the specification")
+	}
+
+	served := make(map[string]bool)
+
+	for _, route := range routes() {
+		if route.webhook != (len(route.paths) == 0) {
+			t.Errorf("route `%s` serves %d paths, only webhooks are not described", route.name, len(route.paths))
+		}
+
+		for _, path := range route.paths {
+			if _, ok := paths[path]; !ok {
+				t.Errorf("route `%s` serves `%s`, not described", route.name, path)
+			}
+
+			served[path] = true
+		}
+	}
+
+	for path := range paths {
+		if !served[path] {
+			t.Errorf("`%s` is described but not served", path)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/kaamebott/pkg/command"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+)
+
+func register(args []string) {
+	fs := flag.NewFlagSet("kaamebott", flag.ExitOnError)
+	fs.Usage = flags.Usage(fs)
+
+	indexerConfig := indexer.Flags(fs, "")
+	commandConfig := command.Flags(fs, "discord")
+
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Loading the sources registers their universes, so they get a command too.
+	_, err := indexer.New(ctx, indexerConfig)
+	logger.FatalfOnErr(ctx, err, "indexer")
+
+	logger.FatalfOnErr(ctx, command.New(commandConfig).Register(ctx), "register")
+}
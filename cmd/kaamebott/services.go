@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"io/fs"
 
 	"github.com/ViBiOh/ChatPotte/discord"
 	"github.com/ViBiOh/ChatPotte/slack"
@@ -11,6 +12,8 @@ import (
 	"github.com/ViBiOh/httputils/v4/pkg/owasp"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 	"github.com/ViBiOh/httputils/v4/pkg/server"
+	"github.com/ViBiOh/kaamebott/pkg/card"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
 	"github.com/ViBiOh/kaamebott/pkg/quote"
 	"github.com/ViBiOh/kaamebott/pkg/search"
 )
@@ -25,6 +28,7 @@ type services struct {
 	renderer *renderer.Service
 
 	search  search.Service
+	quote   quote.Service
 	discord discord.Service
 	slack   slack.Service
 }
@@ -42,17 +46,35 @@ func newServices(ctx context.Context, config configuration, clients clients) (se
 		return output, fmt.Errorf("renderer: %w", err)
 	}
 
-	output.search = search.New(config.search, output.renderer)
+	indexerService, err := indexer.New(ctx, config.indexer)
+	if err != nil {
+		return output, fmt.Errorf("indexer: %w", err)
+	}
+
+	static, err := fs.Sub(content, "static")
+	if err != nil {
+		return output, fmt.Errorf("static: %w", err)
+	}
+
+	cardService, err := card.New(clients.redis, static, clients.telemetry.TracerProvider())
+	if err != nil {
+		return output, fmt.Errorf("card: %w", err)
+	}
+
+	output.search, err = search.New(ctx, config.search, indexerService, clients.redis, output.renderer, cardService)
+	if err != nil {
+		return output, fmt.Errorf("search: %w", err)
+	}
 
 	website := output.renderer.PublicURL("")
-	quoteService := quote.New(website, output.search, clients.redis, clients.telemetry.TracerProvider())
+	output.quote = quote.New(config.quote, website, output.search, clients.redis, clients.telemetry.TracerProvider())
 
-	output.discord, err = discord.New(config.discord, website, quoteService.DiscordHandler, clients.telemetry.TracerProvider())
+	output.discord, err = discord.New(config.discord, website, output.quote.DiscordHandler, clients.telemetry.TracerProvider())
 	if err != nil {
 		return output, fmt.Errorf("discord: %w", err)
 	}
 
-	output.slack = slack.New(config.slack, quoteService.SlackCommand, quoteService.SlackInteract, clients.telemetry.TracerProvider())
+	output.slack = slack.New(config.slack, output.quote.SlackCommand, output.quote.SlackInteract, clients.telemetry.TracerProvider())
 
 	return output, nil
 }
@@ -10,7 +10,9 @@ require (
 	github.com/ViBiOh/httputils/v4 v4.86.1
 	github.com/meilisearch/meilisearch-go v0.36.2
 	go.opentelemetry.io/otel/trace v1.43.0
-	golang.org/x/text v0.36.0
+	golang.org/x/image v0.46.0
+	golang.org/x/net v0.52.0
+	golang.org/x/text v0.42.0
 )
 
 require (
@@ -44,8 +46,7 @@ require (
 	go.opentelemetry.io/otel/sdk/metric v1.43.0 // indirect
 	go.opentelemetry.io/proto/otlp v1.10.0 // indirect
 	go.uber.org/atomic v1.11.0 // indirect
-	golang.org/x/net v0.52.0 // indirect
-	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20260406210006-6f92a3bedf2d // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260406210006-6f92a3bedf2d // indirect
 	google.golang.org/grpc v1.80.0 // indirect
@@ -0,0 +1,278 @@
+package card
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/cache"
+	"github.com/ViBiOh/httputils/v4/pkg/hash"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+	"github.com/ViBiOh/kaamebott/pkg/version"
+	"go.opentelemetry.io/otel/trace"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// Width and Height are the dimensions of a card, the ones recommended for Open Graph images.
+	Width  = 1200
+	Height = 630
+
+	padding    = 64
+	accentSize = 12
+	logoSize   = 128
+
+	maxQuoteSize = 56
+	minQuoteSize = 24
+
+	cacheTTL = time.Hour * 24
+)
+
+var (
+	cachePrefix = version.Redis("card")
+
+	backgroundColor = color.Black
+	accentColor     = color.RGBA{R: 0x64, G: 0x95, B: 0xed, A: 0xff} // cornflowerblue
+	textColor       = color.RGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff} // silver
+	mutedColor      = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+)
+
+type cardKey struct {
+	Universe universe.Universe
+	Quote    model.Quote
+}
+
+type Service struct {
+	static  fs.FS
+	cache   *cache.Cache[cardKey, []byte]
+	regular *opentype.Font
+	bold    *opentype.Font
+	italic  *opentype.Font
+}
+
+func New(redisClient cache.RedisClient, static fs.FS, tracerProvider trace.TracerProvider) (Service, error) {
+	service := Service{
+		static: static,
+	}
+
+	var err error
+
+	if service.regular, err = opentype.Parse(goregular.TTF); err != nil {
+		return service, fmt.Errorf("parse regular font: %w", err)
+	}
+
+	if service.bold, err = opentype.Parse(gobold.TTF); err != nil {
+		return service, fmt.Errorf("parse bold font: %w", err)
+	}
+
+	if service.italic, err = opentype.Parse(goitalic.TTF); err != nil {
+		return service, fmt.Errorf("parse italic font: %w", err)
+	}
+
+	service.cache = cache.New(redisClient, func(key cardKey) string {
+		return fmt.Sprintf("%s:%s:%s:%s", cachePrefix, key.Universe.Name, key.Quote.ID, Version(key.Universe, key.Quote))
+	}, func(ctx context.Context, key cardKey) ([]byte, error) {
+		return service.render(ctx, key.Universe, key.Quote)
+	}, tracerProvider).
+		WithSerializer(pngSerializer{}).
+		WithTTL(cacheTTL)
+
+	return service, nil
+}
+
+// Version identifies the rendered content of a card, so it changes when the quote or its universe is edited by a reindex.
+func Version(item universe.Universe, quote model.Quote) string {
+	return hash.Hash(cardKey{Universe: item, Quote: quote})
+}
+
+// Get returns the PNG card of the quote, cached by its ID and version.
+func (s Service) Get(ctx context.Context, item universe.Universe, quote model.Quote) ([]byte, error) {
+	return s.cache.Get(ctx, cardKey{Universe: item, Quote: quote})
+}
+
+func (s Service) render(ctx context.Context, item universe.Universe, quote model.Quote) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, Width, Height))
+
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rect(0, 0, accentSize, Height), image.NewUniform(accentColor), image.Point{}, draw.Src)
+
+	if err := s.drawText(canvas, s.bold, 28, mutedColor, padding, padding+28, item.Title); err != nil {
+		return nil, fmt.Errorf("draw title: %w", err)
+	}
+
+	if err := s.drawQuote(canvas, quote.Value); err != nil {
+		return nil, fmt.Errorf("draw quote: %w", err)
+	}
+
+	footerY := Height - padding
+
+	if len(quote.Context) != 0 {
+		if err := s.drawText(canvas, s.italic, 28, textColor, padding, footerY, quote.Context); err != nil {
+			return nil, fmt.Errorf("draw context: %w", err)
+		}
+
+		footerY -= 48
+	}
+
+	if item.ShowCharacter && len(quote.Character) != 0 {
+		if err := s.drawText(canvas, s.bold, 36, accentColor, padding, footerY, quote.Character); err != nil {
+			return nil, fmt.Errorf("draw character: %w", err)
+		}
+	}
+
+	if err := s.drawLogo(canvas, item); err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "draw logo", slog.String("universe", item.Name), slog.Any("error", err))
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, canvas); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// drawQuote writes the quote with the largest font size that fits between the title and the footer.
+func (s Service) drawQuote(canvas *image.RGBA, value string) error {
+	const top, bottom = padding + 80, Height - padding - 120
+
+	maxWidth := fixed.I(Width - 2*padding - logoSize)
+	text := fmt.Sprintf("« %s »", strings.TrimSpace(value))
+
+	for size := float64(maxQuoteSize); size >= minQuoteSize; size -= 4 {
+		face, err := newFace(s.regular, size)
+		if err != nil {
+			return err
+		}
+
+		lineHeight := face.Metrics().Height.Ceil()
+		lines := wrap(face, text, maxWidth)
+		maxLines := (bottom - top) / lineHeight
+
+		if len(lines) > maxLines && size > minQuoteSize {
+			continue
+		}
+
+		if len(lines) > maxLines {
+			lines = lines[:maxLines]
+			lines[maxLines-1] += "…"
+		}
+
+		drawer := font.Drawer{Dst: canvas, Src: image.NewUniform(textColor), Face: face}
+
+		for i, line := range lines {
+			drawer.Dot = fixed.P(padding, top+face.Metrics().Ascent.Ceil()+i*lineHeight)
+			drawer.DrawString(line)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+func (s Service) drawText(canvas *image.RGBA, fontFace *opentype.Font, size float64, textColor color.Color, x, y int, text string) error {
+	face, err := newFace(fontFace, size)
+	if err != nil {
+		return err
+	}
+
+	maxWidth := fixed.I(Width - x - padding - logoSize)
+	if lines := wrap(face, text, maxWidth); len(lines) > 1 {
+		text = lines[0] + "…"
+	}
+
+	drawer := font.Drawer{Dst: canvas, Src: image.NewUniform(textColor), Face: face, Dot: fixed.P(x, y)}
+	drawer.DrawString(text)
+
+	return nil
+}
+
+// drawLogo draws the thumbnail of the universe in the bottom right corner, when it's one of the static images.
+func (s Service) drawLogo(canvas *image.RGBA, item universe.Universe) error {
+	if s.static == nil || len(item.Thumbnail) == 0 || strings.HasPrefix(item.Thumbnail, "http") {
+		return nil
+	}
+
+	file, err := s.static.Open(strings.TrimPrefix(item.Thumbnail, "/"))
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	logo, _, err := image.Decode(file)
+	if closeErr := file.Close(); closeErr != nil {
+		err = errors.Join(err, fmt.Errorf("close: %w", closeErr))
+	}
+
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	area := image.Rect(Width-padding-logoSize, Height-padding-logoSize, Width-padding, Height-padding)
+	xdraw.CatmullRom.Scale(canvas, area, logo, logo.Bounds(), xdraw.Over, nil)
+
+	return nil
+}
+
+func newFace(fontFace *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(fontFace, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("new face: %w", err)
+	}
+
+	return face, nil
+}
+
+// wrap splits the text in lines not wider than maxWidth, breaking on spaces.
+func wrap(face font.Face, text string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	var current string
+
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if len(current) != 0 {
+			candidate = current + " " + word
+		}
+
+		if len(current) != 0 && font.MeasureString(face, candidate) > maxWidth {
+			lines = append(lines, current)
+			current = word
+
+			continue
+		}
+
+		current = candidate
+	}
+
+	if len(current) != 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+type pngSerializer struct{}
+
+func (pngSerializer) Encode(payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+func (pngSerializer) Decode(payload []byte) ([]byte, error) {
+	return payload, nil
+}
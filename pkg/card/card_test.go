@@ -0,0 +1,35 @@
+package card
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	item := universe.Universe{Name: "kaamelott", Title: "Kaamelott"}
+	quote := model.Quote{ID: "pas-faux", Value: "C'est pas faux.", Character: "Perceval"}
+
+	version := Version(item, quote)
+
+	if got := Version(item, quote); got != version {
+		t.Errorf("Version() = `%s`, want the same `%s` for the same content", got, version)
+	}
+
+	edited := quote
+	edited.Value = "C'est pas faux !"
+
+	if got := Version(item, edited); got == version {
+		t.Errorf("Version() = `%s` for an edited quote, want another one", got)
+	}
+
+	renamed := item
+	renamed.Title = "Kaamelott, Livre VII"
+
+	if got := Version(renamed, quote); got == version {
+		t.Errorf("Version() = `%s` for an edited universe, want another one", got)
+	}
+}
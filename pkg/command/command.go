@@ -0,0 +1,194 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+const (
+	// QueryOption is the name of the search option of every command.
+	QueryOption = "recherche"
+
+	stringOption = 3
+)
+
+type Service struct {
+	url           string
+	applicationID string
+	botToken      string
+	prune         bool
+}
+
+type Config struct {
+	URL           string
+	ApplicationID string
+	BotToken      string
+	Prune         bool
+}
+
+func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
+	var config Config
+
+	flags.New("URL", "Discord API URL").Prefix(prefix).DocPrefix("discord").StringVar(fs, &config.URL, "https://discord.com/api/v10", overrides)
+	flags.New("ApplicationID", "Application ID").Prefix(prefix).DocPrefix("discord").StringVar(fs, &config.ApplicationID, "", overrides)
+	flags.New("BotToken", "Bot Token").Prefix(prefix).DocPrefix("discord").StringVar(fs, &config.BotToken, "", overrides)
+	flags.New("Prune", "Delete registered commands of unknown universes").Prefix(prefix).DocPrefix("discord").BoolVar(fs, &config.Prune, false, overrides)
+
+	return &config
+}
+
+func New(config *Config) Service {
+	return Service{
+		url:           config.URL,
+		applicationID: config.ApplicationID,
+		botToken:      config.BotToken,
+		prune:         config.Prune,
+	}
+}
+
+// ApplicationCommand is a Discord slash command, as registered through the API.
+type ApplicationCommand struct {
+	ID                       string            `json:"id,omitempty"`
+	Name                     string            `json:"name"`
+	Description              string            `json:"description"`
+	DescriptionLocalizations map[string]string `json:"description_localizations,omitempty"`
+	Options                  []CommandOption   `json:"options,omitempty"`
+}
+
+type CommandOption struct {
+	Type                     int               `json:"type"`
+	Name                     string            `json:"name"`
+	Description              string            `json:"description"`
+	DescriptionLocalizations map[string]string `json:"description_localizations,omitempty"`
+	Required                 bool              `json:"required,omitempty"`
+	Autocomplete             bool              `json:"autocomplete,omitempty"`
+}
+
+// Commands builds the slash command of every known universe.
+func Commands() []ApplicationCommand {
+	universes := universe.All()
+	output := make([]ApplicationCommand, len(universes))
+
+	for i, item := range universes {
+		output[i] = ApplicationCommand{
+			Name:        item.Name,
+			Description: item.Description,
+			DescriptionLocalizations: map[string]string{
+				"fr": fmt.Sprintf("Une citation de %s", item.Title),
+			},
+			Options: []CommandOption{
+				{
+					Type:        stringOption,
+					Name:        QueryOption,
+					Description: "Searched text, refined by filters like perso:name",
+					DescriptionLocalizations: map[string]string{
+						"fr": "Texte recherché, affiné par des filtres comme perso:nom",
+					},
+					Autocomplete: true,
+				},
+			},
+		}
+	}
+
+	return output
+}
+
+// Register creates or updates the commands of every known universe that differ from the registered ones.
+func (s Service) Register(ctx context.Context) error {
+	registered, err := s.list(ctx)
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	for _, command := range Commands() {
+		existing, ok := registered[command.Name]
+		delete(registered, command.Name)
+
+		if ok && same(existing, command) {
+			slog.LogAttrs(ctx, slog.LevelInfo, "Command unchanged", slog.String("command", command.Name))
+			continue
+		}
+
+		if err := s.upsert(ctx, command); err != nil {
+			return fmt.Errorf("upsert `%s`: %w", command.Name, err)
+		}
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "Command registered", slog.String("command", command.Name), slog.Bool("update", ok))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(registered)) {
+		if !s.prune {
+			slog.LogAttrs(ctx, slog.LevelWarn, "Command of unknown universe", slog.String("command", name))
+			continue
+		}
+
+		if err := s.delete(ctx, registered[name].ID); err != nil {
+			return fmt.Errorf("delete `%s`: %w", name, err)
+		}
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "Command deleted", slog.String("command", name))
+	}
+
+	return nil
+}
+
+func (s Service) list(ctx context.Context) (map[string]ApplicationCommand, error) {
+	resp, err := s.request(http.MethodGet, "/commands?with_localizations=true").Send(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := httpjson.Read[[]ApplicationCommand](resp)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	output := make(map[string]ApplicationCommand, len(commands))
+	for _, command := range commands {
+		output[command.Name] = command
+	}
+
+	return output, nil
+}
+
+func (s Service) upsert(ctx context.Context, command ApplicationCommand) error {
+	resp, err := s.request(http.MethodPost, "/commands").JSON(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return request.DiscardBody(resp.Body)
+}
+
+func (s Service) delete(ctx context.Context, id string) error {
+	resp, err := s.request(http.MethodDelete, "/commands/"+id).Send(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return request.DiscardBody(resp.Body)
+}
+
+func (s Service) request(method, path string) request.Request {
+	return request.New().Method(method).URL(fmt.Sprintf("%s/applications/%s%s", s.url, s.applicationID, path)).Header("Authorization", "Bot "+s.botToken)
+}
+
+func same(registered, wanted ApplicationCommand) bool {
+	if registered.Description != wanted.Description || !maps.Equal(registered.DescriptionLocalizations, wanted.DescriptionLocalizations) {
+		return false
+	}
+
+	return slices.EqualFunc(registered.Options, wanted.Options, func(a, b CommandOption) bool {
+		return a.Type == b.Type && a.Name == b.Name && a.Description == b.Description && a.Required == b.Required && a.Autocomplete == b.Autocomplete && maps.Equal(a.DescriptionLocalizations, b.DescriptionLocalizations)
+	})
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type discordServer struct {
+	registered []ApplicationCommand
+	calls      []string
+	mutex      sync.Mutex
+}
+
+func (d *discordServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bot secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if r.URL.Path != "/applications/app/commands" || r.URL.Query().Get("with_localizations") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(d.registered)
+		return
+	}
+
+	call := r.Method + " " + r.URL.Path
+
+	if r.Method == http.MethodPost {
+		var command ApplicationCommand
+		if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		call += " " + command.Name
+	}
+
+	d.calls = append(d.calls, call)
+	w.WriteHeader(http.StatusOK)
+}
+
+func registered(ids ...string) []ApplicationCommand {
+	commands := Commands()
+	for i := range commands {
+		commands[i].ID = ids[i]
+	}
+
+	return commands
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	descriptionChanged := registered("1", "2", "3")
+	descriptionChanged[0].Description = "Get a quote"
+
+	optionChanged := registered("1", "2", "3")
+	optionChanged[1].Options[0].Autocomplete = false
+
+	unknown := append(registered("1", "2", "3"), ApplicationCommand{ID: "4", Name: "astier", Description: "Get an Astier quote"})
+
+	cases := map[string]struct {
+		registered []ApplicationCommand
+		prune      bool
+		want       []string
+	}{
+		"create when missing": {
+			registered: nil,
+			want: []string{
+				"POST /applications/app/commands kaamelott",
+				"POST /applications/app/commands oss117",
+				"POST /applications/app/commands abitbol",
+			},
+		},
+		"nothing changed": {
+			registered: registered("1", "2", "3"),
+			want:       nil,
+		},
+		"description changed": {
+			registered: descriptionChanged,
+			want:       []string{"POST /applications/app/commands kaamelott"},
+		},
+		"option changed": {
+			registered: optionChanged,
+			want:       []string{"POST /applications/app/commands oss117"},
+		},
+		"unknown reported": {
+			registered: unknown,
+			want:       nil,
+		},
+		"unknown pruned": {
+			registered: unknown,
+			prune:      true,
+			want:       []string{"DELETE /applications/app/commands/4"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			discord := &discordServer{registered: testCase.registered}
+
+			server := httptest.NewServer(discord)
+			defer server.Close()
+
+			service := New(&Config{
+				URL:           server.URL,
+				ApplicationID: "app",
+				BotToken:      "secret",
+				Prune:         testCase.prune,
+			})
+
+			if err := service.Register(context.Background()); err != nil {
+				t.Fatalf("Register() = %s", err)
+			}
+
+			if !slices.Equal(discord.calls, testCase.want) {
+				t.Errorf("Register() called %q, want %q", discord.calls, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const abitbolTitleSuffix = " | George Abitbol.fr"
+
+func (s Service) fetchAbitbol(ctx context.Context) ([]model.Quote, error) {
+	index, err := getHTML(ctx, s.abitbolURL)
+	if err != nil {
+		return nil, fmt.Errorf("index: %w", err)
+	}
+
+	var output []model.Quote
+
+	for node := range index.Descendants() {
+		if !hasClass(node, "play") {
+			continue
+		}
+
+		href := attribute(node, "href")
+		if len(href) == 0 {
+			continue
+		}
+
+		quote, err := s.fetchAbitbolPage(ctx, href)
+		if err != nil {
+			return nil, fmt.Errorf("page `%s`: %w", href, err)
+		}
+
+		output = append(output, quote)
+	}
+
+	return output, nil
+}
+
+func (s Service) fetchAbitbolPage(ctx context.Context, href string) (model.Quote, error) {
+	page, err := getHTML(ctx, strings.TrimSuffix(s.abitbolURL, "/")+"/"+strings.TrimPrefix(href, "/"))
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	output := model.Quote{
+		ID: strings.TrimPrefix(strings.TrimPrefix(href, "/"), "v/"),
+	}
+
+	for node := range page.Descendants() {
+		switch {
+		case node.DataAtom == atom.Meta:
+			switch attribute(node, "property") {
+			case "og:title":
+				output.Context = strings.TrimSuffix(attribute(node, "content"), abitbolTitleSuffix)
+			case "og:url":
+				output.URL = attribute(node, "content")
+			case "og:image":
+				output.Image = attribute(node, "content")
+			}
+
+		case node.DataAtom == atom.P && len(output.Value) == 0 && node.Parent != nil && node.Parent.DataAtom == atom.Blockquote:
+			output.Value = textContent(node)
+		}
+	}
+
+	if len(output.Value) == 0 {
+		return output, errors.New("no quote found")
+	}
+
+	return output, nil
+}
+
+func getHTML(ctx context.Context, url string) (*html.Node, error) {
+	resp, err := get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := html.Parse(resp.Body)
+
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	return node, nil
+}
+
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+func hasClass(node *html.Node, class string) bool {
+	return node.Type == html.ElementNode && slices.Contains(strings.Fields(attribute(node, "class")), class)
+}
+
+func textContent(node *html.Node) string {
+	var builder strings.Builder
+
+	for child := range node.Descendants() {
+		if child.Type == html.TextNode {
+			builder.WriteString(child.Data)
+		}
+	}
+
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,86 @@
+package fetcher
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+type Service struct {
+	soundboardURL string
+	gifboardURL   string
+	abitbolURL    string
+}
+
+type Config struct {
+	SoundboardURL string
+	GifboardURL   string
+	AbitbolURL    string
+}
+
+func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
+	var config Config
+
+	flags.New("SoundboardURL", "Kaamelott soundboard list of sounds").Prefix(prefix).DocPrefix("fetcher").StringVar(fs, &config.SoundboardURL, "https://kaamelott-soundboard.2ec0b4.fr/sounds/sounds.b2533e4b.json", overrides)
+	flags.New("GifboardURL", "Kaamelott gifboard list of gifs").Prefix(prefix).DocPrefix("fetcher").StringVar(fs, &config.GifboardURL, "https://raw.githubusercontent.com/kaamelott-gifboard/kaamelott-gifboard/main/gifs.json", overrides)
+	flags.New("AbitbolURL", "George Abitbol website").Prefix(prefix).DocPrefix("fetcher").StringVar(fs, &config.AbitbolURL, "http://george-abitbol.fr", overrides)
+
+	return &config
+}
+
+func New(config *Config) Service {
+	return Service{
+		soundboardURL: config.SoundboardURL,
+		gifboardURL:   config.GifboardURL,
+		abitbolURL:    config.AbitbolURL,
+	}
+}
+
+// Fetch retrieves the collection `name` from its upstream sources, as quotes per collection filename.
+func (s Service) Fetch(ctx context.Context, name string) (map[string][]model.Quote, error) {
+	switch name {
+	case "kaamelott":
+		sounds, err := s.fetchSoundboard(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("soundboard: %w", err)
+		}
+
+		gifs, err := s.fetchGifboard(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("gifboard: %w", err)
+		}
+
+		return map[string][]model.Quote{
+			"kaamelott.json":      sounds,
+			"kaamelott_next.json": gifs,
+		}, nil
+
+	case "abitbol":
+		quotes, err := s.fetchAbitbol(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("abitbol: %w", err)
+		}
+
+		return map[string][]model.Quote{
+			"abitbol.json": quotes,
+		}, nil
+
+	default:
+		// oss117 quotes come from https://github.com/trazip/oss-117-api and https://github.com/shevabam/oss-117-quotes-api, merged by hand.
+		return nil, fmt.Errorf("no fetcher for `%s`, its collection is maintained by hand", name)
+	}
+}
+
+func get(ctx context.Context, url string) (*http.Response, error) {
+	resp, err := request.Get(url).Send(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get `%s`: %w", url, err)
+	}
+
+	return resp, nil
+}
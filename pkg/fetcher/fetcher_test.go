@@ -0,0 +1,136 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+
+	service := New(&Config{
+		SoundboardURL: server.URL + "/sounds.json",
+		GifboardURL:   server.URL + "/gifs.json",
+		AbitbolURL:    server.URL + "/abitbol/",
+	})
+
+	cases := map[string]struct {
+		name    string
+		want    map[string][]model.Quote
+		wantErr bool
+	}{
+		"kaamelott": {
+			name: "kaamelott",
+			want: map[string][]model.Quote{
+				"kaamelott.json": {
+					{
+						ID:        "a_l_aise",
+						Value:     "Vous êtes à l'aise, là ?",
+						Character: "Arthur",
+						Context:   "Livre I, 01 - Heat",
+						URL:       server.URL + "/#son/a_l_aise",
+					},
+					{
+						ID:        "cest_pas_faux",
+						Value:     "C'est pas faux.",
+						Character: "Perceval",
+						Context:   "Livre II, 03 - Les Exploités",
+						URL:       server.URL + "/#son/cest_pas_faux",
+					},
+				},
+				"kaamelott_next.json": {
+					{
+						ID:        "gif-cest-pas-faux",
+						Value:     "C'est pas faux.",
+						Character: "Perceval",
+						Image:     gifboardImagesURL + "cest-pas-faux.gif",
+					},
+					{
+						ID:        "gif-en-meme-temps",
+						Value:     "En même temps...",
+						Character: "Karadoc, Perceval",
+						Image:     gifboardImagesURL + "en-meme-temps.gif",
+					},
+				},
+			},
+		},
+		"abitbol": {
+			name: "abitbol",
+			want: map[string][]model.Quote{
+				"abitbol.json": {
+					{
+						ID:      "classe",
+						Value:   "Monde de merde !",
+						Context: "L'homme le plus classe du monde",
+						URL:     "http://george-abitbol.fr/v/classe",
+						Image:   "http://george-abitbol.fr/images/classe.jpg",
+					},
+					{
+						ID:      "monde",
+						Value:   "Ça c'est une sacrée bonne nouvelle !",
+						Context: "Le grand détournement",
+						URL:     "http://george-abitbol.fr/v/monde",
+					},
+				},
+			},
+		},
+		"by hand": {
+			name:    "oss117",
+			wantErr: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := service.Fetch(context.Background(), testCase.name)
+
+			if testCase.wantErr != (err != nil) {
+				t.Fatalf("Fetch() error = %v, want error %t", err, testCase.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Fetch() = %+v, want %+v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestFetchAbitbolPage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+
+	service := New(&Config{AbitbolURL: server.URL + "/abitbol/"})
+
+	cases := map[string]struct {
+		href string
+	}{
+		"no quote": {
+			href: "/",
+		},
+		"not found": {
+			href: "/v/unknown",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := service.fetchAbitbolPage(context.Background(), testCase.href); err == nil {
+				t.Errorf("fetchAbitbolPage(`%s`) succeeded, want error", testCase.href)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+const gifboardImagesURL = "https://kaamelott-gifboard.fr/gifs/"
+
+type sound struct {
+	Title     string `json:"title"`
+	Character string `json:"character"`
+	Episode   string `json:"episode"`
+	File      string `json:"file"`
+}
+
+type gif struct {
+	Slug       string   `json:"slug"`
+	Quote      string   `json:"quote"`
+	Filename   string   `json:"filename"`
+	Characters []string `json:"characters_speaking"`
+}
+
+func (s Service) fetchSoundboard(ctx context.Context) ([]model.Quote, error) {
+	soundboardURL, err := url.Parse(s.soundboardURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	resp, err := get(ctx, s.soundboardURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sounds, err := httpjson.Read[[]sound](resp)
+	if err != nil {
+		return nil, fmt.Errorf("read sounds: %w", err)
+	}
+
+	output := make([]model.Quote, len(sounds))
+
+	for i, sound := range sounds {
+		id := strings.TrimSuffix(sound.File, ".mp3")
+
+		output[i] = model.Quote{
+			ID:        id,
+			Value:     sound.Title,
+			Character: sound.Character,
+			Context:   sound.Episode,
+			URL:       fmt.Sprintf("%s://%s/#son/%s", soundboardURL.Scheme, soundboardURL.Host, id),
+		}
+	}
+
+	return output, nil
+}
+
+func (s Service) fetchGifboard(ctx context.Context) ([]model.Quote, error) {
+	resp, err := get(ctx, s.gifboardURL)
+	if err != nil {
+		return nil, err
+	}
+
+	gifs, err := httpjson.Read[[]gif](resp)
+	if err != nil {
+		return nil, fmt.Errorf("read gifs: %w", err)
+	}
+
+	output := make([]model.Quote, len(gifs))
+
+	for i, gif := range gifs {
+		output[i] = model.Quote{
+			ID:        "gif-" + gif.Slug,
+			Value:     gif.Quote,
+			Character: strings.Join(gif.Characters, ", "),
+			Image:     gifboardImagesURL + gif.Filename,
+		}
+	}
+
+	return output, nil
+}
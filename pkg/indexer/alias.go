@@ -0,0 +1,40 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+)
+
+const aliasesSuffix = "_aliases.json"
+
+// readAliases reads the former IDs of the quotes of the universe `name`, by their current ID.
+// They were frozen when IDs stopped being a hash of the content, so editing a quote doesn't lose them.
+func (s Service) readAliases(ctx context.Context, name string) (map[string][]string, error) {
+	filename := name + aliasesSuffix
+
+	reader, err := s.source.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "close", slog.String("fn", "indexer.readAliases"), slog.String("item", filename), slog.Any("error", closeErr))
+		}
+	}()
+
+	var aliases map[string][]string
+	if err := json.NewDecoder(reader).Decode(&aliases); err != nil {
+		return nil, fmt.Errorf("load aliases: %w", err)
+	}
+
+	return aliases, nil
+}
@@ -1,14 +1,176 @@
 package indexer
 
-var characterMapping = map[string]string{
-	"attilachefdeshuns":   "attila",
-	"breccanlartisan":     "breccan",
-	"buzitlebarde":        "lebarde",
-	"caiuscamillus":       "caius",
-	"lancelotdulac":       "lancelot",
-	"linterpreteburgonde": "linterprete",
-	"lothdorcanie":        "loth",
-	"mevanwi":             "mevanoui",
-	"monseigneurboniface": "levequeboniface",
-	"doloreskoulechov":    "dolores",
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var partSeparator = regexp.MustCompile(`,| - `)
+
+const charactersSuffix = "_characters.json"
+
+// Character is the canonical identity of a character in a universe, with the other names it appears under in collections.
+type Character struct {
+	Canonical string   `json:"canonical"`
+	Display   string   `json:"display"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+type characterIndex struct {
+	keys       map[string]string
+	characters []Character
+}
+
+// CharacterKey normalizes a character name of the universe `name` to the canonical key it is indexed and filtered by.
+func (s Service) CharacterKey(name, character string) (string, error) {
+	return s.characters[name].key(character)
+}
+
+// Characters lists the characters of the universe `name` having a canonical name.
+func (s Service) Characters(name string) []Character {
+	return slices.Clone(s.characters[name].characters)
+}
+
+func (s Service) readCharacters(ctx context.Context, name string) (characterIndex, error) {
+	filename := name + charactersSuffix
+
+	reader, err := s.source.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return characterIndex{}, nil
+		}
+
+		return characterIndex{}, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "close", slog.String("fn", "indexer.readCharacters"), slog.String("item", filename), slog.Any("error", closeErr))
+		}
+	}()
+
+	var characters []Character
+	if err := json.NewDecoder(reader).Decode(&characters); err != nil {
+		return characterIndex{}, fmt.Errorf("load characters: %w", err)
+	}
+
+	return newCharacterIndex(characters)
+}
+
+func newCharacterIndex(characters []Character) (characterIndex, error) {
+	output := characterIndex{
+		keys:       make(map[string]string),
+		characters: characters,
+	}
+
+	for i, character := range characters {
+		if len(character.Canonical) == 0 {
+			canonical, err := SanitizeName(character.Display)
+			if err != nil {
+				return output, fmt.Errorf("sanitize `%s`: %w", character.Display, err)
+			}
+
+			characters[i].Canonical = canonical
+			character.Canonical = canonical
+		}
+
+		for _, name := range append([]string{character.Canonical, character.Display}, character.Aliases...) {
+			sanitized, err := SanitizeName(name)
+			if err != nil {
+				return output, fmt.Errorf("sanitize `%s`: %w", name, err)
+			}
+
+			if len(sanitized) == 0 {
+				continue
+			}
+
+			if existing, ok := output.keys[sanitized]; ok && existing != character.Canonical {
+				return output, fmt.Errorf("`%s` is an alias of both `%s` and `%s`", name, existing, character.Canonical)
+			}
+
+			output.keys[sanitized] = character.Canonical
+		}
+	}
+
+	return output, nil
+}
+
+func (ci characterIndex) key(name string) (string, error) {
+	sanitized, err := SanitizeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if canonical, ok := ci.keys[sanitized]; ok {
+		return canonical, nil
+	}
+
+	return sanitized, nil
+}
+
+func (ci characterIndex) keysOf(character string) ([]string, error) {
+	var output []string
+
+	for _, name := range partSeparator.Split(character, -1) {
+		key, err := ci.key(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(key) != 0 && !slices.Contains(output, key) {
+			output = append(output, key)
+		}
+	}
+
+	return output, nil
+}
+
+// ContextKey returns the key of the whole context, as indexed among the context keys of a quote.
+func ContextKey(context string) (string, error) {
+	var output strings.Builder
+
+	for _, part := range partSeparator.Split(context, -1) {
+		key, err := SanitizeName(part)
+		if err != nil {
+			return "", err
+		}
+
+		output.WriteString(key)
+	}
+
+	return output.String(), nil
+}
+
+func contextKeys(context string) ([]string, error) {
+	var output []string
+	var cumulative string
+
+	for _, part := range partSeparator.Split(context, -1) {
+		key, err := SanitizeName(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(key) == 0 {
+			continue
+		}
+
+		output = append(output, key)
+
+		if len(cumulative) != 0 {
+			cumulative += key
+			output = append(output, cumulative)
+		} else {
+			cumulative = key
+		}
+	}
+
+	return output, nil
 }
@@ -0,0 +1,162 @@
+package indexer
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCharacterKey(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(context.Background(), &Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("New() = %s", err)
+	}
+
+	cases := map[string]struct {
+		universe  string
+		character string
+		want      string
+	}{
+		"canonical": {
+			universe:  "kaamelott",
+			character: "karadoc",
+			want:      "karadoc",
+		},
+		"alias": {
+			universe:  "kaamelott",
+			character: "Karadoc de Vannes",
+			want:      "karadoc",
+		},
+		"accents": {
+			universe:  "oss117",
+			character: "Dolores KOULECHOV",
+			want:      "dolores",
+		},
+		"other universe": {
+			universe:  "kaamelott",
+			character: "Dolorès Koulechov",
+			want:      "doloreskoulechov",
+		},
+		"unknown": {
+			universe:  "abitbol",
+			character: "George Abitbol",
+			want:      "georgeabitbol",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := service.CharacterKey(testCase.universe, testCase.character)
+			if err != nil {
+				t.Fatalf("CharacterKey() = %s", err)
+			}
+
+			if got != testCase.want {
+				t.Errorf("CharacterKey(`%s`, `%s`) = `%s`, want `%s`", testCase.universe, testCase.character, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestReadCharacters(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		content   string
+		character string
+		want      []string
+		wantErr   bool
+	}{
+		"canonical from display": {
+			content:   `[{"display": "Le Roi Burgonde"}]`,
+			character: "Le roi Burgonde, Arthur",
+			want:      []string{"leroiburgonde", "arthur"},
+		},
+		"aliases deduplicated": {
+			content:   `[{"canonical": "karadoc", "display": "Karadoc", "aliases": ["Karadoc de Vannes"]}]`,
+			character: "Karadoc - Karadoc de Vannes, Perceval",
+			want:      []string{"karadoc", "perceval"},
+		},
+		"alias of two characters": {
+			content: `[{"canonical": "karadoc", "aliases": ["Le Chevalier"]}, {"canonical": "perceval", "aliases": ["le chevalier"]}]`,
+			wantErr: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			service := Service{source: fstest.MapFS{"kaamelott" + charactersSuffix: {Data: []byte(testCase.content)}}}
+
+			characters, err := service.readCharacters(context.Background(), "kaamelott")
+			if testCase.wantErr != (err != nil) {
+				t.Fatalf("readCharacters() = %v, want error %t", err, testCase.wantErr)
+			}
+
+			if testCase.wantErr {
+				return
+			}
+
+			got, err := characters.keysOf(testCase.character)
+			if err != nil {
+				t.Fatalf("keysOf() = %s", err)
+			}
+
+			if !slices.Equal(got, testCase.want) {
+				t.Errorf("keysOf(`%s`) = %q, want %q", testCase.character, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestContextKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		context string
+		want    string
+	}{
+		"book": {
+			context: "Livre II",
+			want:    "livreii",
+		},
+		"book and episode": {
+			context: "Livre II, 3 - Le Code de Chevalerie",
+			want:    "livreii3lecodedechevalerie",
+		},
+		"title": {
+			context: "Le Code de Chevalerie",
+			want:    "lecodedechevalerie",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ContextKey(testCase.context)
+			if err != nil {
+				t.Fatalf("ContextKey() = %s", err)
+			}
+
+			if got != testCase.want {
+				t.Errorf("ContextKey(`%s`) = `%s`, want `%s`", testCase.context, got, testCase.want)
+			}
+
+			keys, err := contextKeys("Livre II, 3 - Le Code de Chevalerie")
+			if err != nil {
+				t.Fatalf("contextKeys() = %s", err)
+			}
+
+			if !slices.Contains(keys, got) {
+				t.Errorf("contextKeys() = %q, want it to contain `%s`", keys, got)
+			}
+		})
+	}
+}
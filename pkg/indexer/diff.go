@@ -0,0 +1,103 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/httputils/v4/pkg/hash"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const documentsPageSize = 1000
+
+// Diff is the difference between a collection and its live index.
+type Diff struct {
+	Added               []Document
+	Removed             []Document
+	Changed             []Document
+	EnrichmentUpdates   int
+	EnrichmentAdds      int
+	EnrichmentAmbiguous int
+	Exists              bool
+}
+
+// Diff compares the collection `name`, as it would be indexed, with the documents of its live index, without modifying anything.
+func (s Service) Diff(ctx context.Context, searchClient meilisearch.ServiceManager, name string) (Diff, error) {
+	documents, enriched, err := s.load(ctx, name)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	output := Diff{
+		EnrichmentUpdates:   enriched.updated,
+		EnrichmentAdds:      enriched.added,
+		EnrichmentAmbiguous: enriched.ambiguous,
+	}
+
+	existings, exists, err := getDocuments(ctx, searchClient, name)
+	if err != nil {
+		return output, fmt.Errorf("get documents: %w", err)
+	}
+
+	output.Exists = exists
+
+	existingsByID := make(map[string]Document, len(existings))
+	for _, existing := range existings {
+		existingsByID[existing.ID] = existing
+	}
+
+	for _, document := range documents {
+		existing, ok := existingsByID[document.ID]
+		if !ok {
+			output.Added = append(output.Added, document)
+			continue
+		}
+
+		delete(existingsByID, document.ID)
+
+		if hash.Hash(existing) != hash.Hash(document) {
+			output.Changed = append(output.Changed, document)
+		}
+	}
+
+	for _, existing := range existings {
+		if _, ok := existingsByID[existing.ID]; ok {
+			output.Removed = append(output.Removed, existing)
+		}
+	}
+
+	return output, nil
+}
+
+func getDocuments(ctx context.Context, searchClient meilisearch.ServiceManager, name string) ([]Document, bool, error) {
+	if _, err := searchClient.GetIndexWithContext(ctx, name); err != nil {
+		if isNotFound(err) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("get index: %w", err)
+	}
+
+	index := searchClient.Index(name)
+
+	var output []Document
+
+	for offset := int64(0); ; offset += documentsPageSize {
+		var results meilisearch.DocumentsResult
+
+		if err := index.GetDocumentsWithContext(ctx, &meilisearch.DocumentsQuery{Offset: offset, Limit: documentsPageSize}, &results); err != nil {
+			return nil, true, fmt.Errorf("fetch page at %d: %w", offset, err)
+		}
+
+		var page []Document
+		if err := results.Results.DecodeInto(&page); err != nil {
+			return nil, true, fmt.Errorf("decode page at %d: %w", offset, err)
+		}
+
+		output = append(output, page...)
+
+		if len(page) == 0 || offset+documentsPageSize >= results.Total {
+			return output, true, nil
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+var tmpIndexPattern = regexp.MustCompile(`_tmp_[0-9a-f]+`)
+
+// fakeMeilisearch keeps indexes in memory, runs tasks synchronously and records the operations on documents and indexes.
+type fakeMeilisearch struct {
+	indexes    map[string][]Document
+	failures   map[int64]string
+	operations []string
+	mutex      sync.Mutex
+}
+
+func newFakeMeilisearch(t *testing.T, indexes map[string][]Document) (*fakeMeilisearch, meilisearch.ServiceManager) {
+	t.Helper()
+
+	fake := &fakeMeilisearch{indexes: indexes, failures: make(map[int64]string)}
+	if fake.indexes == nil {
+		fake.indexes = make(map[string][]Document)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /indexes/{uid}", fake.getIndex)
+	mux.HandleFunc("POST /indexes", fake.createIndex)
+	mux.HandleFunc("DELETE /indexes/{uid}", fake.deleteIndex)
+	mux.HandleFunc("PUT /indexes/{uid}/settings/{setting}", fake.updateSetting)
+	mux.HandleFunc("POST /indexes/{uid}/documents/fetch", fake.fetchDocuments)
+	mux.HandleFunc("POST /indexes/{uid}/documents/delete-batch", fake.deleteDocuments)
+	mux.HandleFunc("POST /indexes/{uid}/documents", fake.addDocuments)
+	mux.HandleFunc("POST /swap-indexes", fake.swapIndexes)
+	mux.HandleFunc("GET /tasks/{uid}", fake.getTask)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return fake, meilisearch.New(server.URL)
+}
+
+func (fm *fakeMeilisearch) record(format string, args ...any) {
+	fm.operations = append(fm.operations, tmpIndexPattern.ReplaceAllString(fmt.Sprintf(format, args...), "_tmp"))
+}
+
+func (fm *fakeMeilisearch) task(w http.ResponseWriter, indexUID, failure string) {
+	uid := int64(len(fm.failures) + 1)
+	fm.failures[uid] = failure
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"taskUid": uid, "indexUid": indexUID, "status": "enqueued"})
+}
+
+func (fm *fakeMeilisearch) getIndex(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := fm.indexes[uid]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"message": "Index not found.", "code": indexNotFound, "type": "invalid_request"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"uid": uid, "primaryKey": id})
+}
+
+func (fm *fakeMeilisearch) createIndex(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	var config meilisearch.IndexConfig
+	_ = json.NewDecoder(r.Body).Decode(&config)
+
+	if _, ok := fm.indexes[config.Uid]; ok {
+		fm.task(w, config.Uid, indexAlreadyExists)
+		return
+	}
+
+	fm.indexes[config.Uid] = []Document{}
+	fm.record("create %s", config.Uid)
+	fm.task(w, config.Uid, "")
+}
+
+func (fm *fakeMeilisearch) deleteIndex(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := fm.indexes[uid]; !ok {
+		fm.task(w, uid, indexNotFound)
+		return
+	}
+
+	delete(fm.indexes, uid)
+	fm.record("drop %s", uid)
+	fm.task(w, uid, "")
+}
+
+func (fm *fakeMeilisearch) updateSetting(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	fm.task(w, r.PathValue("uid"), "")
+}
+
+func (fm *fakeMeilisearch) fetchDocuments(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	var query meilisearch.DocumentsQuery
+	_ = json.NewDecoder(r.Body).Decode(&query)
+
+	documents := fm.indexes[r.PathValue("uid")]
+	start := min(int(query.Offset), len(documents))
+	end := min(start+int(query.Limit), len(documents))
+
+	writeJSON(w, http.StatusOK, map[string]any{"results": documents[start:end], "offset": query.Offset, "limit": query.Limit, "total": len(documents)})
+}
+
+func (fm *fakeMeilisearch) deleteDocuments(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	uid := r.PathValue("uid")
+
+	var ids []string
+	_ = json.NewDecoder(r.Body).Decode(&ids)
+
+	fm.indexes[uid] = slices.DeleteFunc(fm.indexes[uid], func(document Document) bool {
+		return slices.Contains(ids, document.ID)
+	})
+
+	slices.Sort(ids)
+	fm.record("delete %q from %s", ids, uid)
+	fm.task(w, uid, "")
+}
+
+func (fm *fakeMeilisearch) addDocuments(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	uid := r.PathValue("uid")
+
+	var documents []Document
+	_ = json.NewDecoder(r.Body).Decode(&documents)
+
+	ids := make([]string, len(documents))
+
+	for i, document := range documents {
+		ids[i] = document.ID
+
+		if position := slices.IndexFunc(fm.indexes[uid], func(existing Document) bool { return existing.ID == document.ID }); position != -1 {
+			fm.indexes[uid][position] = document
+		} else {
+			fm.indexes[uid] = append(fm.indexes[uid], document)
+		}
+	}
+
+	if len(documents) > 5 {
+		fm.record("add %d to %s", len(documents), uid)
+	} else {
+		slices.Sort(ids)
+		fm.record("add %q to %s", ids, uid)
+	}
+
+	fm.task(w, uid, "")
+}
+
+func (fm *fakeMeilisearch) swapIndexes(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	var params []meilisearch.SwapIndexesParams
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	for _, param := range params {
+		name, tmpName := param.Indexes[0], param.Indexes[1]
+
+		if param.Rename {
+			fm.indexes[name] = fm.indexes[tmpName]
+			delete(fm.indexes, tmpName)
+			fm.record("rename %s to %s", tmpName, name)
+		} else {
+			fm.indexes[name], fm.indexes[tmpName] = fm.indexes[tmpName], fm.indexes[name]
+			fm.record("swap %s with %s", name, tmpName)
+		}
+	}
+
+	fm.task(w, "", "")
+}
+
+func (fm *fakeMeilisearch) getTask(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	uid, _ := strconv.ParseInt(r.PathValue("uid"), 10, 64)
+
+	task := map[string]any{"uid": uid, "status": meilisearch.TaskStatusSucceeded}
+	if failure := fm.failures[uid]; len(failure) != 0 {
+		task["status"] = meilisearch.TaskStatusFailed
+		task["error"] = map[string]any{"message": failure, "code": failure}
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func writeJSON(w http.ResponseWriter, status int, content any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(content)
+}
+
+// newDiffService serves a collection with an enrichment updating `pas-faux` and adding a new quote.
+func newDiffService() Service {
+	return Service{
+		source: fstest.MapFS{
+			"kaamelott.json":      {Data: []byte(`[{"id": "pas-faux", "value": "C'est pas faux.", "character": "Perceval"}, {"id": "a-l-aise", "value": "Vous êtes à l'aise, là ?", "character": "Arthur"}, {"id": "sloubi", "value": "Sloubi un, sloubi deux", "character": "Perceval"}]`)},
+			"kaamelott_next.json": {Data: []byte(`[{"id": "gif-pas-faux", "value": "C'est pas faux !", "character": "Perceval", "image": "pas-faux.gif"}, {"id": "gif-graal", "value": "On en a gros !", "character": "Perceval", "image": "gros.gif"}]`)},
+		},
+		enrichmentThreshold: 0.85,
+	}
+}
+
+func documentIDs(documents []Document) []string {
+	output := make([]string, len(documents))
+	for i, document := range documents {
+		output[i] = document.ID
+	}
+
+	return output
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	service := newDiffService()
+
+	documents, err := service.Load(context.Background(), "kaamelott")
+	if err != nil {
+		t.Fatalf("Load() = %s", err)
+	}
+
+	changed := documents[1]
+	changed.Value = "Vous êtes à l'aise ?"
+
+	cases := map[string]struct {
+		indexes     map[string][]Document
+		wantAdded   []string
+		wantChanged []string
+		wantRemoved []string
+		wantExists  bool
+	}{
+		"no index": {
+			wantAdded: []string{"pas-faux", "a-l-aise", "sloubi", "gif-graal"},
+		},
+		"up to date": {
+			indexes:    map[string][]Document{"kaamelott": documents},
+			wantExists: true,
+		},
+		"differences": {
+			indexes: map[string][]Document{"kaamelott": {
+				documents[0],
+				changed,
+				{Quote: model.Quote{ID: "removed", Value: "Cuillère !"}},
+			}},
+			wantAdded:   []string{"sloubi", "gif-graal"},
+			wantChanged: []string{"a-l-aise"},
+			wantRemoved: []string{"removed"},
+			wantExists:  true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeMeilisearch(t, testCase.indexes)
+
+			got, err := service.Diff(context.Background(), client, "kaamelott")
+			if err != nil {
+				t.Fatalf("Diff() = %s", err)
+			}
+
+			if got.Exists != testCase.wantExists {
+				t.Errorf("Diff().Exists = %t, want %t", got.Exists, testCase.wantExists)
+			}
+
+			for name, ids := range map[string][2][]string{
+				"Added":   {documentIDs(got.Added), testCase.wantAdded},
+				"Changed": {documentIDs(got.Changed), testCase.wantChanged},
+				"Removed": {documentIDs(got.Removed), testCase.wantRemoved},
+			} {
+				if !slices.Equal(ids[0], ids[1]) {
+					t.Errorf("Diff().%s = %q, want %q", name, ids[0], ids[1])
+				}
+			}
+
+			if got.EnrichmentUpdates != 1 || got.EnrichmentAdds != 1 {
+				t.Errorf("Diff() enrichments = %d updates and %d adds, want one of each", got.EnrichmentUpdates, got.EnrichmentAdds)
+			}
+
+			if len(fake.operations) != 0 {
+				t.Errorf("Diff() modified the index: %q", fake.operations)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+// ErrUnknownBook occurs when a book number is malformed.
+var ErrUnknownBook = errors.New("unknown book")
+
+var (
+	episodePattern = regexp.MustCompile(`^Livre ([IVX]+),? (\d+)\s*-\s*(.+)$`)
+	arabicPattern  = regexp.MustCompile(`^\d{1,2}$`)
+	romanPattern   = regexp.MustCompile(`^X{0,3}(IX|IV|V?I{0,3})$`)
+	romanNumerals  = map[byte]int{'I': 1, 'V': 5, 'X': 10}
+)
+
+// BookNumber parses a book number written in arabic numerals, or in canonical roman numerals up to XXXIX.
+func BookNumber(value string) (int, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	if arabicPattern.MatchString(value) {
+		if number, err := strconv.Atoi(value); err == nil && number != 0 {
+			return number, nil
+		}
+	}
+
+	if len(value) == 0 || !romanPattern.MatchString(value) {
+		return 0, fmt.Errorf("`%s`: %w", value, ErrUnknownBook)
+	}
+
+	var output, previous int
+
+	for i := len(value) - 1; i >= 0; i-- {
+		numeral := romanNumerals[value[i]]
+
+		if numeral < previous {
+			output -= numeral
+		} else {
+			output += numeral
+			previous = numeral
+		}
+	}
+
+	return output, nil
+}
+
+// parseEpisode fills the book, episode number and title of a quote from a context like `Livre II, 03 - Le Dialogue de Paix`, unless already provided.
+func parseEpisode(quote model.Quote) model.Quote {
+	if quote.Book != 0 {
+		return quote
+	}
+
+	matches := episodePattern.FindStringSubmatch(strings.TrimSpace(quote.Context))
+	if matches == nil {
+		return quote
+	}
+
+	book, err := BookNumber(matches[1])
+	if err != nil {
+		return quote
+	}
+
+	episode, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return quote
+	}
+
+	quote.Book = book
+	quote.Episode = episode
+	quote.EpisodeTitle = strings.TrimSpace(matches[3])
+
+	return quote
+}
@@ -0,0 +1,76 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBookNumber(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		value   string
+		want    int
+		wantErr error
+	}{
+		"arabic": {
+			value: "3",
+			want:  3,
+		},
+		"roman": {
+			value: "IV",
+			want:  4,
+		},
+		"lowercase": {
+			value: " vi ",
+			want:  6,
+		},
+		"largest": {
+			value: "XXXIX",
+			want:  39,
+		},
+		"zero": {
+			value:   "0",
+			wantErr: ErrUnknownBook,
+		},
+		"negative": {
+			value:   "-1",
+			wantErr: ErrUnknownBook,
+		},
+		"repeated numeral": {
+			value:   "IIII",
+			wantErr: ErrUnknownBook,
+		},
+		"invalid subtraction": {
+			value:   "VX",
+			wantErr: ErrUnknownBook,
+		},
+		"repeated subtraction": {
+			value:   "IIX",
+			wantErr: ErrUnknownBook,
+		},
+		"empty": {
+			value:   "",
+			wantErr: ErrUnknownBook,
+		},
+		"text": {
+			value:   "deux",
+			wantErr: ErrUnknownBook,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := BookNumber(testCase.value)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Fatalf("BookNumber(`%s`) = %v, want %v", testCase.value, err, testCase.wantErr)
+			}
+
+			if got != testCase.want {
+				t.Errorf("BookNumber(`%s`) = %d, want %d", testCase.value, got, testCase.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package indexer
 
 import (
+	"cmp"
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"path"
-	"strings"
+	"net/http"
+	"regexp"
+	"slices"
 	"time"
 
 	"github.com/ViBiOh/httputils/v4/pkg/hash"
@@ -15,49 +18,196 @@ import (
 	"github.com/meilisearch/meilisearch-go"
 )
 
+const (
+	batchSize       = 500
+	ambiguityMargin = 0.05
+
+	indexAlreadyExists = "index_already_exists"
+	indexNotFound      = "index_not_found"
+)
+
 var (
-	id          = "id"
-	indexFolder = "indexes"
+	id                   = "id"
+	filterableAttributes = []any{"characterKeys", "contextKeys", "aliases", "book", "episode"}
+	sortableAttributes   = []string{"book", "episode"}
+	sourceIDPattern      = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
 )
 
 //go:embed indexes
-var fs embed.FS
+var content embed.FS
+
+// Document is a quote as stored in the index, with its normalized filtering keys and the former IDs it still answers to.
+type Document struct {
+	model.Quote
+	CharacterKeys []string `json:"characterKeys,omitempty"`
+	ContextKeys   []string `json:"contextKeys,omitempty"`
+	Aliases       []string `json:"aliases,omitempty"`
+}
 
-func Index(ctx context.Context, searchClient meilisearch.ServiceManager, name string) error {
-	filename := name + ".json"
+// Index updates the live index of the collection `name` with its differences, or fully replaces it if configured or if it doesn't exist yet.
+func (s Service) Index(ctx context.Context, searchClient meilisearch.ServiceManager, name string) error {
+	if !s.fullReplace {
+		diff, err := s.Diff(ctx, searchClient, name)
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+
+		if diff.Exists {
+			return update(ctx, searchClient, name, diff)
+		}
+	}
+
+	return s.replace(ctx, searchClient, name)
+}
 
-	quotes, indexName, err := readQuotes(ctx, filename)
+func update(ctx context.Context, searchClient meilisearch.ServiceManager, name string, diff Diff) error {
+	index, err := getIndex(ctx, searchClient, name)
 	if err != nil {
-		return fmt.Errorf("read quote for `%s`: %w", filename, err)
+		return fmt.Errorf("get index: %w", err)
+	}
+
+	removedIDs := make([]string, len(diff.Removed))
+	for i, document := range diff.Removed {
+		removedIDs[i] = document.ID
 	}
 
-	index, err := getIndex(ctx, searchClient, indexName)
+	for batch := range slices.Chunk(removedIDs, batchSize) {
+		deleteTask, err := index.DeleteDocumentsWithContext(ctx, batch, &meilisearch.DocumentOptions{})
+		if err != nil {
+			return fmt.Errorf("delete documents: %w", err)
+		}
+
+		if err := waitTask(ctx, index.WaitForTaskWithContext, deleteTask); err != nil {
+			return fmt.Errorf("wait delete: %w", err)
+		}
+	}
+
+	if err := addQuotes(ctx, index, append(diff.Added, diff.Changed...)); err != nil {
+		return fmt.Errorf("add quotes: %w", err)
+	}
+
+	return nil
+}
+
+// replace builds the collection `name` into a temporary index, then swaps it with the live one so searches never see a partial index.
+func (s Service) replace(ctx context.Context, searchClient meilisearch.ServiceManager, name string) error {
+	documents, err := s.Load(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	tmpName := fmt.Sprintf("%s_tmp_%s", name, hash.Hash(documents)[:8])
+
+	if err := deleteIndex(ctx, searchClient, tmpName); err != nil {
+		return fmt.Errorf("clean temporary index: %w", err)
+	}
+
+	index, err := getIndex(ctx, searchClient, tmpName)
 	if err != nil {
 		return fmt.Errorf("get index: %w", err)
 	}
 
-	if err := replaceQuotes(ctx, index, quotes); err != nil {
-		return fmt.Errorf("replace quotes: %w", err)
+	if err := addQuotes(ctx, index, documents); err != nil {
+		return fmt.Errorf("add quotes: %w", err)
+	}
+
+	if err := swapIndex(ctx, searchClient, name, tmpName); err != nil {
+		return fmt.Errorf("swap index: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the collection `name` with its enrichment applied, as it would be indexed.
+func (s Service) Load(ctx context.Context, name string) ([]Document, error) {
+	documents, _, err := s.load(ctx, name)
+
+	return documents, err
+}
+
+type enrichment struct {
+	updated   int
+	added     int
+	ambiguous int
+}
+
+func (s Service) load(ctx context.Context, name string) ([]Document, enrichment, error) {
+	filename := name + ".json"
+
+	quotes, err := s.readQuotes(ctx, name, filename)
+	if err != nil {
+		return nil, enrichment{}, fmt.Errorf("read quote for `%s`: %w", filename, err)
 	}
 
-	enriched, _, err := readQuotes(ctx, name+"_next.json")
+	enriched, err := s.readQuotes(ctx, name, name+"_next.json")
 	if err != nil {
 		slog.LogAttrs(ctx, slog.LevelWarn, "load quote enrichment", slog.String("name", name), slog.Any("error", err))
 	}
 
-	if err := enrichQuotes(ctx, index, quotes, enriched); err != nil {
-		return fmt.Errorf("enrich quotes: %w", err)
+	aliases, err := s.readAliases(ctx, name)
+	if err != nil {
+		return nil, enrichment{}, fmt.Errorf("read aliases: %w", err)
 	}
 
-	return nil
+	characters := s.characters[name]
+
+	merged, err := mergeQuotes(quotes, enriched, characters, s.enrichmentThreshold)
+	if err != nil {
+		return nil, enrichment{}, fmt.Errorf("merge quotes: %w", err)
+	}
+
+	positions := make(map[string]int, len(quotes))
+	for i, quote := range quotes {
+		positions[quote.ID] = i
+	}
+
+	for _, quote := range merged.updated {
+		quotes[positions[quote.ID]] = quote
+	}
+
+	quotes = append(quotes, merged.added...)
+	output := make([]Document, len(quotes))
+
+	for i, quote := range quotes {
+		output[i].Quote = parseEpisode(quote)
+
+		output[i].Aliases = documentAliases(quote.ID, merged.claimed[quote.ID], aliases)
+
+		if output[i].CharacterKeys, err = characters.keysOf(quote.Character); err != nil {
+			return nil, enrichment{}, fmt.Errorf("character keys `%s`: %w", quote.Character, err)
+		}
+
+		if output[i].ContextKeys, err = contextKeys(quote.Context); err != nil {
+			return nil, enrichment{}, fmt.Errorf("context keys `%s`: %w", quote.Context, err)
+		}
+	}
+
+	return output, enrichment{updated: len(merged.updated), added: len(merged.added), ambiguous: len(merged.ambiguous)}, nil
 }
 
-func readQuotes(ctx context.Context, filename string) ([]model.Quote, string, error) {
-	indexFile := path.Join(indexFolder, filename)
+// documentAliases lists the former IDs of a document: its own ones, and the ones of the enrichment merged into it, that doesn't have a document anymore.
+func documentAliases(id, enrichmentID string, aliases map[string][]string) []string {
+	candidates := slices.Clone(aliases[id])
+	if len(enrichmentID) != 0 {
+		candidates = append(append(candidates, enrichmentID), aliases[enrichmentID]...)
+	}
+
+	var output []string
 
-	reader, err := fs.Open(indexFile)
+	for _, alias := range candidates {
+		if alias != id && !slices.Contains(output, alias) {
+			output = append(output, alias)
+		}
+	}
+
+	return output
+}
+
+// readQuotes reads the quotes of `filename` with their stable ID.
+func (s Service) readQuotes(ctx context.Context, name, filename string) ([]model.Quote, error) {
+	reader, err := s.source.Open(filename)
 	if err != nil {
-		return nil, "", fmt.Errorf("open file: %w", err)
+		return nil, fmt.Errorf("open file: %w", err)
 	}
 
 	defer func() {
@@ -68,14 +218,39 @@ func readQuotes(ctx context.Context, filename string) ([]model.Quote, string, er
 
 	var quotes []model.Quote
 	if err := json.NewDecoder(reader).Decode(&quotes); err != nil {
-		return nil, "", fmt.Errorf("load quotes: %w", err)
+		return nil, fmt.Errorf("load quotes: %w", err)
 	}
 
 	for i, quote := range quotes {
-		quotes[i].ID = hash.Hash(quote)
+		if quotes[i].ID, err = stableID(name, quote); err != nil {
+			return nil, fmt.Errorf("id of quote #%d: %w", i, err)
+		}
 	}
 
-	return quotes, path.Base(strings.TrimSuffix(indexFile, ".json")), nil
+	return quotes, nil
+}
+
+// stableID honors the source ID when it's a valid document ID, or derives one from what identifies the quote, so fixing a typo doesn't change it.
+func stableID(name string, quote model.Quote) (string, error) {
+	if len(quote.ID) != 0 {
+		if sourceIDPattern.MatchString(quote.ID) {
+			return quote.ID, nil
+		}
+
+		return hash.String(name + "/" + quote.ID), nil
+	}
+
+	value, err := SanitizeName(quote.Value)
+	if err != nil {
+		return "", fmt.Errorf("sanitize value: %w", err)
+	}
+
+	character, err := SanitizeName(quote.Character)
+	if err != nil {
+		return "", fmt.Errorf("sanitize character: %w", err)
+	}
+
+	return hash.String(name + "/" + value + "/" + character), nil
 }
 
 func getIndex(ctx context.Context, search meilisearch.ServiceManager, name string) (meilisearch.IndexManager, error) {
@@ -84,111 +259,244 @@ func getIndex(ctx context.Context, search meilisearch.ServiceManager, name strin
 		return nil, fmt.Errorf("create index: %w", err)
 	}
 
-	if _, err := search.WaitForTaskWithContext(ctx, createTask.TaskUID, time.Second); err != nil {
+	if err := waitTask(ctx, search.WaitForTaskWithContext, createTask); err != nil && !isTaskFailure(err, indexAlreadyExists) {
 		return nil, fmt.Errorf("wait index: %w", err)
 	}
 
-	return search.Index(name), nil
+	index := search.Index(name)
+
+	filterableTask, err := index.UpdateFilterableAttributes(&filterableAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("update filterable attributes: %w", err)
+	}
+
+	if err := waitTask(ctx, index.WaitForTaskWithContext, filterableTask); err != nil {
+		return nil, fmt.Errorf("wait filterable attributes: %w", err)
+	}
+
+	sortableTask, err := index.UpdateSortableAttributes(&sortableAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("update sortable attributes: %w", err)
+	}
+
+	if err := waitTask(ctx, index.WaitForTaskWithContext, sortableTask); err != nil {
+		return nil, fmt.Errorf("wait sortable attributes: %w", err)
+	}
+
+	return index, nil
+}
+
+func addQuotes(ctx context.Context, index meilisearch.IndexManager, quotes []Document) error {
+	for batch := range slices.Chunk(quotes, batchSize) {
+		addTask, err := index.AddDocumentsWithContext(ctx, batch, &meilisearch.DocumentOptions{PrimaryKey: &id})
+		if err != nil {
+			return fmt.Errorf("add documents: %w", err)
+		}
+
+		if err := waitTask(ctx, index.WaitForTaskWithContext, addTask); err != nil {
+			return fmt.Errorf("wait add: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func replaceQuotes(ctx context.Context, index meilisearch.IndexManager, quotes []model.Quote) error {
-	deleteTask, err := index.DeleteAllDocuments(&meilisearch.DocumentOptions{})
+func swapIndex(ctx context.Context, search meilisearch.ServiceManager, name, tmpName string) error {
+	_, err := search.GetIndexWithContext(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("get live index: %w", err)
+	}
+
+	exists := err == nil
+
+	swapTask, err := search.SwapIndexesWithContext(ctx, []*meilisearch.SwapIndexesParams{{Indexes: []string{name, tmpName}, Rename: !exists}})
 	if err != nil {
-		return fmt.Errorf("delete documents: %w", err)
+		return fmt.Errorf("swap indexes: %w", err)
 	}
 
-	if _, err := index.WaitForTaskWithContext(ctx, deleteTask.TaskUID, time.Second); err != nil {
+	if err := waitTask(ctx, search.WaitForTaskWithContext, swapTask); err != nil {
+		return fmt.Errorf("wait swap: %w", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	if err := deleteIndex(ctx, search, tmpName); err != nil {
+		return fmt.Errorf("delete previous index: %w", err)
+	}
+
+	return nil
+}
+
+func deleteIndex(ctx context.Context, search meilisearch.ServiceManager, name string) error {
+	deleteTask, err := search.DeleteIndexWithContext(ctx, name)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("delete index: %w", err)
+	}
+
+	if err := waitTask(ctx, search.WaitForTaskWithContext, deleteTask); err != nil && !isTaskFailure(err, indexNotFound) {
 		return fmt.Errorf("wait delete: %w", err)
 	}
 
-	addTask, err := index.AddDocuments(quotes, &meilisearch.DocumentOptions{PrimaryKey: &id})
+	return nil
+}
+
+func waitTask(ctx context.Context, wait func(context.Context, int64, time.Duration) (*meilisearch.Task, error), taskInfo *meilisearch.TaskInfo) error {
+	task, err := wait(ctx, taskInfo.TaskUID, time.Second)
 	if err != nil {
-		return fmt.Errorf("add documents: %w", err)
+		return err
 	}
 
-	if _, err := index.WaitForTaskWithContext(ctx, addTask.TaskUID, time.Second); err != nil {
-		return fmt.Errorf("wait add: %w", err)
+	if task.Status == meilisearch.TaskStatusFailed {
+		return taskFailure{uid: task.UID, code: task.Error.Code, message: task.Error.Message}
 	}
 
 	return nil
 }
 
-func enrichQuotes(ctx context.Context, index meilisearch.IndexManager, quotes, enriched []model.Quote) error {
+// taskFailure is a Meilisearch task that ran but failed, with the code telling why.
+type taskFailure struct {
+	code    string
+	message string
+	uid     int64
+}
+
+func (tf taskFailure) Error() string {
+	return fmt.Sprintf("task %d failed: %s", tf.uid, tf.message)
+}
+
+func isTaskFailure(err error, code string) bool {
+	failure, ok := errors.AsType[taskFailure](err)
+
+	return ok && failure.code == code
+}
+
+func isNotFound(err error) bool {
+	meiliError, ok := errors.AsType[*meilisearch.Error](err)
+
+	return ok && meiliError.StatusCode == http.StatusNotFound
+}
+
+type merge struct {
+	// claimed is the ID of the enrichment merged into each updated quote, by quote ID.
+	claimed   map[string]string
+	updated   []model.Quote
+	added     []model.Quote
+	ambiguous []ambiguousMatch
+}
+
+// ambiguousMatch is an enrichment close to several quotes, it's merged into the first candidate.
+// When its best candidate has already been claimed by another enrichment, it's kept as its own quote instead.
+type ambiguousMatch struct {
+	enrichment model.Quote
+	candidates []model.Quote
+	claimedBy  string
+}
+
+type scoredQuote struct {
+	quote model.Quote
+	score float64
+}
+
+// mergeQuotes attaches each enrichment to the most similar quote of one of its characters, or to any quote if it has no character.
+// A quote is enriched at most once, the first enrichment claims it.
+func mergeQuotes(quotes, enriched []model.Quote, characters characterIndex, threshold float64) (merge, error) {
+	output := merge{claimed: make(map[string]string)}
+
 	existingsPerCharacter := make(map[string][]model.Quote)
+	sanitizedValues := make(map[string]string, len(quotes))
+	claimed := output.claimed
 
 	for _, quote := range quotes {
-		sanitizedCharacter, err := sanitizeName(quote.Character)
+		keys, err := characters.keysOf(quote.Character)
 		if err != nil {
-			return fmt.Errorf("sanitize character `%s`: %w", quote.Character, err)
+			return output, fmt.Errorf("sanitize character `%s`: %w", quote.Character, err)
 		}
 
-		existingsPerCharacter[sanitizedCharacter] = append(existingsPerCharacter[sanitizedCharacter], quote)
-	}
+		for _, key := range keys {
+			existingsPerCharacter[key] = append(existingsPerCharacter[key], quote)
+		}
 
-	var toAdd []model.Quote
-	var toUpdate []model.Quote
+		if sanitizedValues[quote.ID], err = SanitizeName(quote.Value); err != nil {
+			return output, fmt.Errorf("sanitize value `%s`: %w", quote.Value, err)
+		}
+	}
 
 	for _, quote := range enriched {
-		sanitizedQuote, err := sanitizeName(quote.Value)
+		sanitizedQuote, err := SanitizeName(quote.Value)
 		if err != nil {
-			return fmt.Errorf("sanitize quote: %w", err)
+			return output, fmt.Errorf("sanitize quote: %w", err)
 		}
 
-		var found bool
+		keys, err := characters.keysOf(quote.Character)
+		if err != nil {
+			return output, fmt.Errorf("sanitize character `%s`: %w", quote.Character, err)
+		}
 
-		for character := range strings.SplitSeq(quote.Character, ",") {
-			sanitizedCharacter, err := sanitizeName(character)
-			if err != nil {
-				return fmt.Errorf("sanitize character `%s`: %w", character, err)
-			}
+		candidates := quotes
+		if len(keys) != 0 {
+			candidates = nil
 
-			if mapped, ok := characterMapping[sanitizedCharacter]; ok {
-				sanitizedCharacter = mapped
+			for _, key := range keys {
+				candidates = append(candidates, existingsPerCharacter[key]...)
 			}
+		}
 
-			for _, existing := range existingsPerCharacter[sanitizedCharacter] {
-				sanitizedExisting, err := sanitizeName(existing.Value)
-				if err != nil {
-					return fmt.Errorf("sanitize value `%s`: %w", existing.Value, err)
-				}
+		var matches []scoredQuote
+		seen := make(map[string]bool, len(candidates))
 
-				if sanitizedExisting == sanitizedQuote {
-					existing.Image = quote.Image
+		for _, existing := range candidates {
+			if seen[existing.ID] {
+				continue
+			}
 
-					toUpdate = append(toUpdate, existing)
+			seen[existing.ID] = true
 
-					found = true
-					break
-				}
+			if score := similarity(sanitizedValues[existing.ID], sanitizedQuote, threshold); score >= threshold {
+				matches = append(matches, scoredQuote{quote: existing, score: score})
 			}
 		}
 
-		if !found {
-			toAdd = append(toAdd, quote)
+		if len(matches) == 0 {
+			output.added = append(output.added, quote)
+			continue
 		}
-	}
 
-	if len(toUpdate) != 0 {
-		updateTask, err := index.UpdateDocuments(toUpdate, &meilisearch.DocumentOptions{PrimaryKey: &id})
-		if err != nil {
-			return fmt.Errorf("update quote: %w", err)
-		}
+		slices.SortStableFunc(matches, func(a, b scoredQuote) int {
+			return cmp.Compare(b.score, a.score)
+		})
 
-		if _, err := index.WaitForTaskWithContext(ctx, updateTask.TaskUID, time.Second); err != nil {
-			return fmt.Errorf("wait update: %w", err)
-		}
-	}
+		best := matches[0].quote
 
-	if len(toAdd) != 0 {
-		addTask, err := index.AddDocuments(toAdd, &meilisearch.DocumentOptions{PrimaryKey: &id})
-		if err != nil {
-			return fmt.Errorf("add quote: %w", err)
+		if claimer, ok := claimed[best.ID]; ok {
+			output.added = append(output.added, quote)
+			output.ambiguous = append(output.ambiguous, ambiguousMatch{enrichment: quote, candidates: []model.Quote{best}, claimedBy: claimer})
+
+			continue
 		}
 
-		if _, err := index.WaitForTaskWithContext(ctx, addTask.TaskUID, time.Second); err != nil {
-			return fmt.Errorf("wait add: %w", err)
+		claimed[best.ID] = quote.ID
+		best.Image = quote.Image
+
+		output.updated = append(output.updated, best)
+
+		if len(matches) > 1 && matches[0].score-matches[1].score < ambiguityMargin {
+			ambiguous := ambiguousMatch{enrichment: quote}
+
+			for _, match := range matches {
+				if matches[0].score-match.score < ambiguityMargin {
+					ambiguous.candidates = append(ambiguous.candidates, match.quote)
+				}
+			}
+
+			output.ambiguous = append(output.ambiguous, ambiguous)
 		}
 	}
 
-	return nil
+	return output, nil
 }
@@ -0,0 +1,286 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/hash"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+func TestMergeQuotes(t *testing.T) {
+	t.Parallel()
+
+	quotes := []model.Quote{
+		{ID: "pas-faux", Value: "C'est pas faux.", Character: "Perceval"},
+		{ID: "a-l-aise", Value: "Vous êtes à l'aise, là ?", Character: "Arthur"},
+	}
+
+	enriched := []model.Quote{
+		{ID: "gif-pas-faux", Value: "C'est pas faux !", Character: "Perceval", Image: "pas-faux.gif"},
+		{ID: "gif-pas-faux-bis", Value: "C'est pas faux...", Character: "Perceval", Image: "pas-faux-bis.gif"},
+		{ID: "gif-sloubi", Value: "Sloubi un, sloubi deux", Character: "Perceval", Image: "sloubi.gif"},
+	}
+
+	got, err := mergeQuotes(quotes, enriched, characterIndex{}, 0.85)
+	if err != nil {
+		t.Fatalf("mergeQuotes() = %s", err)
+	}
+
+	if len(got.updated) != 1 || got.updated[0].ID != "pas-faux" || got.updated[0].Image != "pas-faux.gif" {
+		t.Errorf("mergeQuotes().updated = %+v, want `pas-faux` with the first image", got.updated)
+	}
+
+	if len(got.added) != 2 || got.added[0].ID != "gif-pas-faux-bis" || got.added[1].ID != "gif-sloubi" {
+		t.Errorf("mergeQuotes().added = %+v, want `gif-pas-faux-bis` and `gif-sloubi`", got.added)
+	}
+
+	if len(got.ambiguous) != 1 || got.ambiguous[0].enrichment.ID != "gif-pas-faux-bis" || got.ambiguous[0].claimedBy != "gif-pas-faux" {
+		t.Errorf("mergeQuotes().ambiguous = %+v, want `gif-pas-faux-bis` claimed by `gif-pas-faux`", got.ambiguous)
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	t.Parallel()
+
+	service := Service{
+		source: fstest.MapFS{
+			"kaamelott.json":         {Data: []byte(`[{"id": "pas-faux", "value": "C'est pas faux, edited."}, {"value": "Sloubi"}]`)},
+			"kaamelott_aliases.json": {Data: []byte(`{"pas-faux": ["0123456789abcdef"]}`)},
+		},
+	}
+
+	documents, err := service.Load(context.Background(), "kaamelott")
+	if err != nil {
+		t.Fatalf("Load() = %s", err)
+	}
+
+	if len(documents) != 2 {
+		t.Fatalf("Load() = %d documents, want 2", len(documents))
+	}
+
+	if !slices.Equal(documents[0].Aliases, []string{"0123456789abcdef"}) {
+		t.Errorf("Load()[0].Aliases = %q, want the frozen alias", documents[0].Aliases)
+	}
+
+	if len(documents[1].Aliases) != 0 {
+		t.Errorf("Load()[1].Aliases = %q, want none", documents[1].Aliases)
+	}
+}
+
+// TestFormerIDs checks that the IDs from the content hashes, and the stable ID of every quote, still resolve to a document once enrichments are merged.
+func TestFormerIDs(t *testing.T) {
+	t.Parallel()
+
+	// baselineQuote is the shape of quotes when their ID was the hash of their content.
+	type baselineQuote struct {
+		ID        string
+		Value     string
+		Character string
+		Context   string
+		URL       string
+		Image     string
+	}
+
+	service, err := New(context.Background(), &Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("New() = %s", err)
+	}
+
+	for _, item := range universe.All() {
+		documents, err := service.Load(context.Background(), item.Name)
+		if err != nil {
+			t.Fatalf("Load(`%s`) = %s", item.Name, err)
+		}
+
+		resolved := make(map[string]bool)
+		for _, document := range documents {
+			resolved[document.ID] = true
+
+			for _, alias := range document.Aliases {
+				resolved[alias] = true
+			}
+		}
+
+		for _, filename := range []string{item.Name + ".json", item.Name + "_next.json"} {
+			payload, err := fs.ReadFile(service.source, filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			if err != nil {
+				t.Fatalf("read `%s`: %s", filename, err)
+			}
+
+			var quotes []model.Quote
+			if err := json.Unmarshal(payload, &quotes); err != nil {
+				t.Fatalf("unmarshal `%s`: %s", filename, err)
+			}
+
+			for position, quote := range quotes {
+				id, err := stableID(item.Name, quote)
+				if err != nil {
+					t.Fatalf("stableID() = %s", err)
+				}
+
+				if !resolved[id] {
+					t.Errorf("%s#%d: ID `%s` doesn't resolve", filename, position, id)
+				}
+
+				if former := hash.Hash(baselineQuote{quote.ID, quote.Value, quote.Character, quote.Context, quote.URL, quote.Image}); !resolved[former] {
+					t.Errorf("%s#%d: former ID `%s` doesn't resolve", filename, position, former)
+				}
+			}
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	documents, err := newDiffService().Load(context.Background(), "kaamelott")
+	if err != nil {
+		t.Fatalf("Load() = %s", err)
+	}
+
+	changed := documents[1]
+	changed.Value = "Vous êtes à l'aise ?"
+
+	live := []Document{documents[0], changed, {Quote: model.Quote{ID: "removed", Value: "Cuillère !"}}}
+
+	cases := map[string]struct {
+		indexes     map[string][]Document
+		fullReplace bool
+		want        []string
+	}{
+		"created": {
+			want: []string{
+				"create kaamelott_tmp",
+				`add ["a-l-aise" "gif-graal" "pas-faux" "sloubi"] to kaamelott_tmp`,
+				"rename kaamelott_tmp to kaamelott",
+			},
+		},
+		"up to date": {
+			indexes: map[string][]Document{"kaamelott": slices.Clone(documents)},
+		},
+		"incremental": {
+			indexes: map[string][]Document{"kaamelott": slices.Clone(live)},
+			want: []string{
+				`delete ["removed"] from kaamelott`,
+				`add ["a-l-aise" "gif-graal" "sloubi"] to kaamelott`,
+			},
+		},
+		"full replace": {
+			indexes:     map[string][]Document{"kaamelott": slices.Clone(live)},
+			fullReplace: true,
+			want: []string{
+				"create kaamelott_tmp",
+				`add ["a-l-aise" "gif-graal" "pas-faux" "sloubi"] to kaamelott_tmp`,
+				"swap kaamelott with kaamelott_tmp",
+				"drop kaamelott_tmp",
+			},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			service := newDiffService()
+			service.fullReplace = testCase.fullReplace
+
+			fake, client := newFakeMeilisearch(t, testCase.indexes)
+
+			if err := service.Index(context.Background(), client, "kaamelott"); err != nil {
+				t.Fatalf("Index() = %s", err)
+			}
+
+			if !slices.Equal(fake.operations, testCase.want) {
+				t.Errorf("Index() operations = %q, want %q", fake.operations, testCase.want)
+			}
+
+			if len(fake.indexes) != 1 {
+				t.Errorf("Index() left %d indexes, want only the live one", len(fake.indexes))
+			}
+
+			got := make([]string, 0, len(documents))
+			for _, document := range fake.indexes["kaamelott"] {
+				got = append(got, hash.Hash(document))
+			}
+
+			want := make([]string, 0, len(documents))
+			for _, document := range documents {
+				want = append(want, hash.Hash(document))
+			}
+
+			slices.Sort(got)
+			slices.Sort(want)
+
+			if !slices.Equal(got, want) {
+				t.Errorf("Index() indexed %d documents, want the %d of the collection", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestWaitTask(t *testing.T) {
+	t.Parallel()
+
+	errWait := errors.New("timeout")
+
+	cases := map[string]struct {
+		status   meilisearch.TaskStatus
+		code     string
+		waitErr  error
+		wantErr  bool
+		wantCode string
+	}{
+		"succeeded": {
+			status: meilisearch.TaskStatusSucceeded,
+		},
+		"failed": {
+			status:   meilisearch.TaskStatusFailed,
+			code:     indexAlreadyExists,
+			wantErr:  true,
+			wantCode: indexAlreadyExists,
+		},
+		"wait error": {
+			waitErr: errWait,
+			wantErr: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			wait := func(_ context.Context, uid int64, _ time.Duration) (*meilisearch.Task, error) {
+				if testCase.waitErr != nil {
+					return nil, testCase.waitErr
+				}
+
+				task := &meilisearch.Task{UID: uid, Status: testCase.status}
+				task.Error.Code = testCase.code
+
+				return task, nil
+			}
+
+			err := waitTask(context.Background(), wait, &meilisearch.TaskInfo{TaskUID: 42})
+			if testCase.wantErr != (err != nil) {
+				t.Fatalf("waitTask() = %v, want error %t", err, testCase.wantErr)
+			}
+
+			if got := isTaskFailure(err, indexAlreadyExists); got != (testCase.wantCode == indexAlreadyExists) {
+				t.Errorf("isTaskFailure(%v) = %t", err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+// Issue is a problem found in a collection file. Errors make the collection unfit for indexing, others are warnings.
+type Issue struct {
+	File     string
+	ID       string
+	Message  string
+	Position int
+	Error    bool
+}
+
+// Lint checks the collection `name` and its enrichment.
+func (s Service) Lint(ctx context.Context, name string) ([]Issue, error) {
+	current, ok := universe.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown universe `%s`", name)
+	}
+
+	filename := name + ".json"
+
+	quotes, err := s.readQuotes(ctx, name, filename)
+	if err != nil {
+		return []Issue{{File: filename, Message: err.Error(), Position: -1, Error: true}}, nil
+	}
+
+	issues := lintQuotes(current, filename, quotes)
+
+	enrichmentName := name + "_next.json"
+
+	enriched, err := s.readQuotes(ctx, name, enrichmentName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return issues, nil
+		}
+
+		return append(issues, Issue{File: enrichmentName, Message: err.Error(), Position: -1, Error: true}), nil
+	}
+
+	issues = append(issues, lintQuotes(current, enrichmentName, enriched)...)
+
+	enrichmentIssues, err := lintEnrichment(name, enrichmentName, quotes, enriched, s.characters[name], s.enrichmentThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("lint enrichment: %w", err)
+	}
+
+	return append(issues, enrichmentIssues...), nil
+}
+
+func lintQuotes(current universe.Universe, filename string, quotes []model.Quote) []Issue {
+	var issues []Issue
+
+	seenIDs := make(map[string]int, len(quotes))
+	seenValues := make(map[string]int, len(quotes))
+
+	for position, quote := range quotes {
+		issue := func(isError bool, format string, args ...any) {
+			issues = append(issues, Issue{File: filename, Position: position, ID: quote.ID, Message: fmt.Sprintf(format, args...), Error: isError})
+		}
+
+		if len(quote.Value) == 0 && len(quote.Context) == 0 && len(quote.Image) == 0 {
+			issue(true, "empty value, without context nor image")
+		}
+
+		if current.ShowCharacter && len(quote.Character) == 0 {
+			issue(true, "missing character")
+		}
+
+		if err := checkURL(quote.URL); err != nil {
+			issue(true, "invalid url: %s", err)
+		}
+
+		if err := checkURL(quote.Image); err != nil {
+			issue(true, "invalid image: %s", err)
+		}
+
+		if previous, ok := seenIDs[quote.ID]; ok {
+			issue(true, "duplicate of quote #%d", previous)
+		} else {
+			seenIDs[quote.ID] = position
+		}
+
+		sanitized, err := SanitizeName(quote.Value)
+		if err != nil {
+			issue(true, "sanitize value: %s", err)
+			continue
+		}
+
+		if len(sanitized) == 0 {
+			continue
+		}
+
+		if previous, ok := seenValues[sanitized]; ok {
+			issue(false, "same value as quote #%d", previous)
+		} else {
+			seenValues[sanitized] = position
+		}
+	}
+
+	return issues
+}
+
+func lintEnrichment(collectionName, filename string, quotes, enriched []model.Quote, characters characterIndex, threshold float64) ([]Issue, error) {
+	knownCharacters := make(map[string]bool)
+
+	for _, quote := range quotes {
+		keys, err := characters.keysOf(quote.Character)
+		if err != nil {
+			return nil, fmt.Errorf("sanitize character `%s`: %w", quote.Character, err)
+		}
+
+		for _, key := range keys {
+			knownCharacters[key] = true
+		}
+	}
+
+	merged, err := mergeQuotes(quotes, enriched, characters, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+
+	ambiguousMatches := make(map[string]ambiguousMatch, len(merged.ambiguous))
+	for _, match := range merged.ambiguous {
+		ambiguousMatches[match.enrichment.ID] = match
+	}
+
+	var issues []Issue
+	unknownCharacters := make(map[string]bool)
+
+	unmatched := len(merged.added)
+	for _, match := range merged.ambiguous {
+		if len(match.claimedBy) != 0 {
+			unmatched--
+		}
+	}
+
+	if unmatched != 0 {
+		issues = append(issues, Issue{File: filename, Position: -1, Message: fmt.Sprintf("%d enrichments match no quote, they will be added as new ones (listed by a dry run)", unmatched)})
+	}
+
+	for position, quote := range enriched {
+		match, ambiguous := ambiguousMatches[quote.ID]
+
+		switch {
+		case ambiguous && len(match.claimedBy) != 0:
+			issues = append(issues, Issue{File: filename, Position: position, ID: quote.ID, Message: fmt.Sprintf("enrichment matches quote `%s`, already enriched by `%s`, it will be added as a new one", match.candidates[0].ID, match.claimedBy)})
+
+		case ambiguous:
+			ids := make([]string, len(match.candidates))
+			for i, candidate := range match.candidates {
+				ids[i] = candidate.ID
+			}
+
+			issues = append(issues, Issue{File: filename, Position: position, ID: quote.ID, Message: fmt.Sprintf("enrichment is as close to quotes `%s`, it will be merged into the first one", strings.Join(ids, "`, `"))})
+
+		}
+
+		for _, name := range partSeparator.Split(quote.Character, -1) {
+			key, err := characters.key(name)
+			if err != nil {
+				return nil, fmt.Errorf("sanitize character `%s`: %w", name, err)
+			}
+
+			if len(key) == 0 || knownCharacters[key] || unknownCharacters[key] {
+				continue
+			}
+
+			unknownCharacters[key] = true
+			issues = append(issues, Issue{File: filename, Position: position, ID: quote.ID, Message: fmt.Sprintf("character `%s` (`%s`) is unknown in the collection, an alias may be missing in `%s`", name, key, collectionName+charactersSuffix)})
+		}
+	}
+
+	return issues, nil
+}
+
+func checkURL(value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || len(parsed.Host) == 0 {
+		return fmt.Errorf("`%s` is not an absolute http url", value)
+	}
+
+	return nil
+}
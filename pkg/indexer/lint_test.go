@@ -0,0 +1,95 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+func TestLintQuotes(t *testing.T) {
+	t.Parallel()
+
+	current := universe.Universe{Name: "abitbol"}
+
+	cases := map[string]struct {
+		quote     model.Quote
+		wantError bool
+	}{
+		"value": {
+			quote: model.Quote{ID: "monde", Value: "Monde de merde !"},
+		},
+		"context only": {
+			quote: model.Quote{ID: "b62dc9d8", Context: "Meuh !!!", Image: "https://george-abitbol.fr/doc/thumbs/171.jpg"},
+		},
+		"image only": {
+			quote: model.Quote{ID: "b62dc9d8", Image: "https://george-abitbol.fr/doc/thumbs/171.jpg"},
+		},
+		"empty": {
+			quote:     model.Quote{ID: "b62dc9d8"},
+			wantError: true,
+		},
+		"invalid url": {
+			quote:     model.Quote{ID: "monde", Value: "Monde de merde !", URL: "/v/monde"},
+			wantError: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var gotError bool
+			for _, issue := range lintQuotes(current, "abitbol.json", []model.Quote{testCase.quote}) {
+				gotError = gotError || issue.Error
+			}
+
+			if gotError != testCase.wantError {
+				t.Errorf("lintQuotes() error = %t, want %t", gotError, testCase.wantError)
+			}
+		})
+	}
+}
+
+func TestLintEnrichment(t *testing.T) {
+	t.Parallel()
+
+	quotes := []model.Quote{{ID: "pas-faux", Value: "C'est pas faux.", Character: "Perceval"}}
+	enriched := []model.Quote{
+		{ID: "gif-pas-faux", Value: "C'est pas faux !", Character: "Perceval"},
+		{ID: "gif-sloubi", Value: "Sloubi un", Character: "Perceval"},
+		{ID: "gif-graal", Value: "Le Graal", Character: "Perceval"},
+	}
+
+	issues, err := lintEnrichment("kaamelott", "kaamelott_next.json", quotes, enriched, characterIndex{}, 0.85)
+	if err != nil {
+		t.Fatalf("lintEnrichment() = %s", err)
+	}
+
+	if len(issues) != 1 || issues[0].Position != -1 || issues[0].Message != "2 enrichments match no quote, they will be added as new ones (listed by a dry run)" {
+		t.Errorf("lintEnrichment() = %+v, want a single count of unmatched enrichments", issues)
+	}
+}
+
+func TestLintCollections(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(context.Background(), &Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("New() = %s", err)
+	}
+
+	for _, item := range universe.All() {
+		issues, err := service.Lint(context.Background(), item.Name)
+		if err != nil {
+			t.Fatalf("Lint(`%s`) = %s", item.Name, err)
+		}
+
+		for _, issue := range issues {
+			if issue.Error {
+				t.Errorf("%s#%d `%s`: %s", issue.File, issue.Position, issue.ID, issue.Message)
+			}
+		}
+	}
+}
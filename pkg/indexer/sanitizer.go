@@ -29,7 +29,7 @@ var (
 	pathEscape   = regexp.MustCompile(`\.{2,}(?m)`)
 )
 
-func sanitizeName(name string) (string, error) {
+func SanitizeName(name string) (string, error) {
 	withoutLigatures := strings.ToLower(name)
 	for key, value := range transliterations {
 		if strings.Contains(withoutLigatures, key) {
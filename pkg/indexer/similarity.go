@@ -0,0 +1,42 @@
+package indexer
+
+// Levenshtein is the number of single byte edits needed to turn `a` into `b`.
+func Levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// similarity is the edit distance of two sanitized values normalized between 0 and 1. Values too different in length to reach `threshold` are not compared.
+func similarity(a, b string, threshold float64) float64 {
+	if a == b {
+		return 1
+	}
+
+	shortest, longest := min(len(a), len(b)), max(len(a), len(b))
+	if float64(shortest)/float64(longest) < threshold {
+		return 0
+	}
+
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
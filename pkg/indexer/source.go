@@ -0,0 +1,141 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+const (
+	indexFolder   = "indexes"
+	universesFile = "universes.json"
+)
+
+type Service struct {
+	source              fs.FS
+	characters          map[string]characterIndex
+	fullReplace         bool
+	enrichmentThreshold float64
+}
+
+type Config struct {
+	Source              string
+	FullReplace         bool
+	EnrichmentThreshold float64
+}
+
+func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
+	var config Config
+
+	flags.New("Source", "Directory or file:// URL of collections, layered over the embedded ones").Prefix(prefix).DocPrefix("indexer").StringVar(fs, &config.Source, "", overrides)
+	flags.New("FullReplace", "Rebuild the whole index and swap it, instead of applying differences").Prefix(prefix).DocPrefix("indexer").BoolVar(fs, &config.FullReplace, false, overrides)
+	flags.New("EnrichmentThreshold", "Similarity from 0 to 1 for an enrichment to be merged into a quote").Prefix(prefix).DocPrefix("indexer").Float64Var(fs, &config.EnrichmentThreshold, 0.85, overrides)
+
+	return &config
+}
+
+// New creates an indexer reading collections from the configured source first, then from the embedded ones. Universes described in a `universes.json` of the source are registered, and the characters of every universe are loaded.
+func New(ctx context.Context, config *Config) (Service, error) {
+	embedded, err := fs.Sub(content, indexFolder)
+	if err != nil {
+		return Service{}, fmt.Errorf("embedded collections: %w", err)
+	}
+
+	service := Service{
+		source:              embedded,
+		fullReplace:         config.FullReplace,
+		enrichmentThreshold: config.EnrichmentThreshold,
+	}
+
+	if len(config.Source) != 0 {
+		if err := service.layerSource(ctx, config.Source); err != nil {
+			return service, err
+		}
+	}
+
+	universes := universe.All()
+	service.characters = make(map[string]characterIndex, len(universes))
+
+	for _, item := range universes {
+		if service.characters[item.Name], err = service.readCharacters(ctx, item.Name); err != nil {
+			return service, fmt.Errorf("read characters of `%s`: %w", item.Name, err)
+		}
+	}
+
+	return service, nil
+}
+
+func (s *Service) layerSource(ctx context.Context, source string) error {
+	directory := strings.TrimPrefix(source, "file://")
+
+	info, err := os.Stat(directory)
+	if err != nil {
+		return fmt.Errorf("stat source: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("source `%s` is not a directory", directory)
+	}
+
+	s.source = layeredFS{os.DirFS(directory), s.source}
+
+	universes, err := s.readUniverses(ctx)
+	if err != nil {
+		return fmt.Errorf("read universes: %w", err)
+	}
+
+	universe.Register(universes...)
+
+	return nil
+}
+
+func (s Service) readUniverses(ctx context.Context) ([]universe.Universe, error) {
+	reader, err := s.source.Open(universesFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "close", slog.String("fn", "indexer.readUniverses"), slog.Any("error", closeErr))
+		}
+	}()
+
+	var universes []universe.Universe
+	if err := json.NewDecoder(reader).Decode(&universes); err != nil {
+		return nil, fmt.Errorf("load universes: %w", err)
+	}
+
+	return universes, nil
+}
+
+// layeredFS opens a file from the first layer having it.
+type layeredFS []fs.FS
+
+func (l layeredFS) Open(name string) (fs.File, error) {
+	var err error
+
+	for _, layer := range l {
+		var file fs.File
+
+		file, err = layer.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+	}
+
+	return nil, err
+}
@@ -7,4 +7,8 @@ type Quote struct {
 	Context   string `json:"context"`
 	URL       string `json:"url"`
 	Image     string `json:"image"`
+
+	Book         int    `json:"book,omitempty"`
+	Episode      int    `json:"episode,omitempty"`
+	EpisodeTitle string `json:"episodeTitle,omitempty"`
 }
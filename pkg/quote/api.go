@@ -0,0 +1,172 @@
+package quote
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
+	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/search"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+const (
+	defaultAPILimit = 10
+	maxAPILimit     = 100
+)
+
+// QuotesPage is a page of the quotes matching a search, with the estimated count of matching ones.
+type QuotesPage struct {
+	Items []model.Quote `json:"items"`
+	Total int           `json:"total"`
+}
+
+// Router registers handlers by pattern, like an http.ServeMux.
+type Router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// NewAPIMux serves the quotes as JSON, to be mounted under APIPath.
+func (s Service) NewAPIMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	s.RegisterAPI(mux)
+
+	return mux
+}
+
+// RegisterAPI adds the routes of the API to the router, relative to APIPath. Each of them has to be described in apiRoutes.
+func (s Service) RegisterAPI(router Router) {
+	router.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	router.HandleFunc("GET /v1/universes", s.handleUniverses)
+	router.HandleFunc("GET /v1/{universe}/quotes", s.handleQuotes)
+	router.HandleFunc("GET /v1/{universe}/quotes/{id}", s.handleQuote)
+	router.HandleFunc("GET /v1/{universe}/random", s.handleRandom)
+}
+
+// APIPaths lists the paths registered by RegisterAPI, from the root of the website.
+func APIPaths() []string {
+	var router patternRecorder
+	Service{}.RegisterAPI(&router)
+
+	output := make([]string, len(router))
+	for i, pattern := range router {
+		_, path, _ := strings.Cut(pattern, " ")
+		output[i] = APIPath + path
+	}
+
+	return output
+}
+
+type patternRecorder []string
+
+func (p *patternRecorder) HandleFunc(pattern string, _ func(http.ResponseWriter, *http.Request)) {
+	*p = append(*p, pattern)
+}
+
+func (s Service) handleUniverses(w http.ResponseWriter, r *http.Request) {
+	httpjson.WriteArray(r.Context(), w, http.StatusOK, universe.All())
+}
+
+func (s Service) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	item, ok := apiUniverse(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil {
+		httperror.BadRequest(ctx, w, fmt.Errorf("offset: %w", err))
+		return
+	}
+
+	limit, err := intParam(query.Get("limit"), defaultAPILimit)
+	if err != nil || limit < 1 || limit > maxAPILimit {
+		httperror.BadRequest(ctx, w, fmt.Errorf("limit must be between 1 and %d", maxAPILimit))
+		return
+	}
+
+	text, filters := search.ParseQuery(query.Get("q"))
+
+	quotes, total, err := s.search.SearchHits(ctx, item.Name, text, filters, offset, limit)
+	if err != nil {
+		handleAPIError(w, r, err)
+		return
+	}
+
+	httpjson.Write(ctx, w, http.StatusOK, QuotesPage{Items: quotes, Total: total})
+}
+
+func (s Service) handleQuote(w http.ResponseWriter, r *http.Request) {
+	item, ok := apiUniverse(w, r)
+	if !ok {
+		return
+	}
+
+	quote, err := s.search.GetByID(r.Context(), item.Name, r.PathValue("id"))
+	if err != nil {
+		handleAPIError(w, r, err)
+		return
+	}
+
+	httpjson.Write(r.Context(), w, http.StatusOK, quote)
+}
+
+func (s Service) handleRandom(w http.ResponseWriter, r *http.Request) {
+	item, ok := apiUniverse(w, r)
+	if !ok {
+		return
+	}
+
+	quote, err := s.search.Random(r.Context(), item.Name, nil)
+	if err != nil {
+		handleAPIError(w, r, err)
+		return
+	}
+
+	httpjson.Write(r.Context(), w, http.StatusOK, quote)
+}
+
+func apiUniverse(w http.ResponseWriter, r *http.Request) (universe.Universe, bool) {
+	name := r.PathValue("universe")
+
+	item, ok := universe.Get(name)
+	if !ok {
+		httperror.NotFound(r.Context(), w, fmt.Errorf("unknown universe `%s`", name))
+	}
+
+	return item, ok
+}
+
+func handleAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, search.ErrNotFound) || errors.Is(err, search.ErrIndexNotFound) {
+		httperror.NotFound(r.Context(), w, err)
+		return
+	}
+
+	httperror.HandleError(r.Context(), w, err)
+}
+
+func intParam(value string, fallback int) (int, error) {
+	if len(value) == 0 {
+		return fallback, nil
+	}
+
+	output, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if output < 0 {
+		return 0, errors.New("must be positive")
+	}
+
+	return output, nil
+}
@@ -0,0 +1,116 @@
+package quote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+	"github.com/ViBiOh/kaamebott/pkg/card"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/search"
+)
+
+func newTestService(t *testing.T, searchURL string) Service {
+	t.Helper()
+
+	indexerService, err := indexer.New(context.Background(), &indexer.Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("indexer: %s", err)
+	}
+
+	searchService, err := search.New(context.Background(), &search.Config{URL: searchURL}, indexerService, redis.Noop{}, nil, card.Service{})
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+
+	return New(&Config{}, "https://kaamebott.vibioh.fr", searchService, redis.Noop{}, nil)
+}
+
+func TestAPI(t *testing.T) {
+	t.Parallel()
+
+	mux := newTestService(t, "").NewAPIMux()
+
+	cases := map[string]struct {
+		path string
+		want int
+	}{
+		"universes": {
+			path: "/v1/universes",
+			want: http.StatusOK,
+		},
+		"search": {
+			path: "/v1/kaamelott/quotes?q=pas+faux+perso:perceval&limit=5",
+			want: http.StatusOK,
+		},
+		"unknown universe": {
+			path: "/v1/astier/quotes",
+			want: http.StatusNotFound,
+		},
+		"negative offset": {
+			path: "/v1/kaamelott/quotes?offset=-1",
+			want: http.StatusBadRequest,
+		},
+		"limit too high": {
+			path: "/v1/kaamelott/quotes?limit=101",
+			want: http.StatusBadRequest,
+		},
+		"malformed book": {
+			path: "/v1/kaamelott/quotes?q=livre:IIII",
+			want: http.StatusBadRequest,
+		},
+		"quote": {
+			path: "/v1/abitbol/quotes/b62dc9d8",
+			want: http.StatusOK,
+		},
+		"former id": {
+			path: "/v1/kaamelott/quotes/03762b7e3d0047a0",
+			want: http.StatusOK,
+		},
+		"unknown quote": {
+			path: "/v1/kaamelott/quotes/graal",
+			want: http.StatusNotFound,
+		},
+		"random": {
+			path: "/v1/oss117/random",
+			want: http.StatusOK,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			writer := httptest.NewRecorder()
+			mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+			if got := writer.Code; got != testCase.want {
+				t.Errorf("GET %s = %d, want %d: %s", testCase.path, got, testCase.want, writer.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIMissingIndex(t *testing.T) {
+	t.Parallel()
+
+	meilisearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Index not found.", "code": "index_not_found", "type": "invalid_request", "link": ""}`))
+	}))
+	t.Cleanup(meilisearch.Close)
+
+	mux := newTestService(t, meilisearch.URL).NewAPIMux()
+
+	for _, path := range []string{"/v1/kaamelott/quotes?q=graal", "/v1/kaamelott/quotes/cest_pas_faux2", "/v1/kaamelott/random"} {
+		writer := httptest.NewRecorder()
+		mux.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if got := writer.Code; got != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want %d: %s", path, got, http.StatusNotFound, writer.Body.String())
+		}
+	}
+}
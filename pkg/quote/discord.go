@@ -8,20 +8,25 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ViBiOh/ChatPotte/discord"
+	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
 	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+	"github.com/ViBiOh/kaamebott/pkg/command"
 	"github.com/ViBiOh/kaamebott/pkg/model"
 	"github.com/ViBiOh/kaamebott/pkg/search"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
 	"github.com/ViBiOh/kaamebott/pkg/version"
 )
 
 const (
-	queryParam = "recherche"
+	queryParam = command.QueryOption
+	idPrefix   = "id:"
 
-	kaamelottName = "kaamelott"
-	oss117Name    = "oss117"
-	abitbolName   = "abitbol"
+	maxDiscordChoices   = 25
+	maxSelectOptionLen  = 100
+	autocompleteTimeout = 2 * time.Second
 )
 
 var (
@@ -29,12 +34,6 @@ var (
 	cancelAction = fmt.Sprintf("action=%s", url.QueryEscape(cancelValue))
 )
 
-var indexes = map[string]string{
-	kaamelottName: kaamelottName,
-	oss117Name:    oss117Name,
-	abitbolName:   abitbolName,
-}
-
 func (s Service) DiscordHandler(ctx context.Context, webhook discord.InteractionRequest) (discord.InteractionResponse, bool, func(context.Context) discord.InteractionResponse) {
 	var err error
 
@@ -46,20 +45,28 @@ func (s Service) DiscordHandler(ctx context.Context, webhook discord.Interaction
 		return discord.NewEphemeral(false, err.Error()), false, nil
 	}
 
+	if webhook.Type == discord.ApplicationCommandAutocompleteInteraction {
+		return s.autocomplete(ctx, index, webhook), false, nil
+	}
+
 	action, query, offset, err := s.getQuery(ctx, webhook)
 	if err != nil {
 		return discord.NewEphemeral(false, err.Error()), false, nil
 	}
 
 	switch action {
-	case nextValue:
-		return s.handleSearch(ctx, index, query, offset), false, nil
+	case nextValue, pickValue:
+		return s.handleSearch(ctx, index, query, webhook.ChannelID, offset, webhook.Type == discord.MessageComponentInteraction), false, nil
 
 	case sendValue:
 		quote, err := s.search.GetByID(ctx, index, query)
 		if err != nil {
 			slog.LogAttrs(ctx, slog.LevelError, "get by id", slog.String("index", index), slog.String("query", query), slog.Any("error", err))
-			return discord.NewError(true, err), false, nil
+			return discord.NewError(webhook.Type == discord.MessageComponentInteraction, err), false, nil
+		}
+
+		if webhook.Type == discord.ApplicationCommandInteraction {
+			return s.quoteMessage(webhook.Member.User.ID, index, quote), false, nil
 		}
 
 		return s.quoteResponse(webhook.Member.User.ID, index, quote)
@@ -68,7 +75,7 @@ func (s Service) DiscordHandler(ctx context.Context, webhook discord.Interaction
 		return discord.NewEphemeral(true, "Ok, not now."), true, nil
 
 	default:
-		return s.handleSearch(ctx, index, query, 0), false, nil
+		return s.handleSearch(ctx, index, query, webhook.ChannelID, 0, false), false, nil
 	}
 }
 
@@ -77,16 +84,16 @@ func (s Service) checkRequest(webhook discord.InteractionRequest) (string, error
 	switch webhook.Type {
 	case discord.MessageComponentInteraction:
 		command = webhook.Message.Interaction.Name
-	case discord.ApplicationCommandInteraction:
+	case discord.ApplicationCommandInteraction, discord.ApplicationCommandAutocompleteInteraction:
 		command = webhook.Data.Name
 	}
 
-	index, ok := indexes[command]
+	universe, ok := universe.Get(command)
 	if !ok {
 		return "", fmt.Errorf("unknown command `%s`", command)
 	}
 
-	return index, nil
+	return universe.Name, nil
 }
 
 func (s Service) getQuery(ctx context.Context, webhook discord.InteractionRequest) (string, string, int, error) {
@@ -101,7 +108,7 @@ func (s Service) getQuery(ctx context.Context, webhook discord.InteractionReques
 		case sendValue:
 			return sendValue, values.Get("id"), 0, nil
 
-		case nextValue:
+		case nextValue, previousValue:
 			offset, err := strconv.Atoi(values.Get("offset"))
 			if err != nil {
 				return "", "", 0, fmt.Errorf("offset is not numeric: %w", err)
@@ -109,48 +116,86 @@ func (s Service) getQuery(ctx context.Context, webhook discord.InteractionReques
 
 			return nextValue, values.Get("search"), offset, nil
 
+		case pickValue:
+			if len(webhook.Data.Values) == 0 {
+				return "", "", 0, errors.New("no choice selected")
+			}
+
+			offset, err := strconv.Atoi(webhook.Data.Values[0])
+			if err != nil {
+				return "", "", 0, fmt.Errorf("choice is not numeric: %w", err)
+			}
+
+			return pickValue, values.Get("search"), offset, nil
+
 		case cancelValue:
 			return cancelValue, "", 0, nil
 		}
 
 	case discord.ApplicationCommandInteraction:
 		for _, option := range webhook.Data.Options {
-			if strings.EqualFold(option.Name, queryParam) {
-				return nextValue, option.Value, 0, nil
+			if !strings.EqualFold(option.Name, queryParam) {
+				continue
 			}
+
+			if id, ok := strings.CutPrefix(option.Value, idPrefix); ok {
+				return sendValue, id, 0, nil
+			}
+
+			return nextValue, option.Value, 0, nil
 		}
 	}
 
 	return "", "", 0, nil
 }
 
-func (s Service) handleSearch(ctx context.Context, indexName, query string, offset int) discord.InteractionResponse {
-	quote, err := s.search.Search(ctx, indexName, query, offset)
+func (s Service) handleSearch(ctx context.Context, indexName, query, channel string, offset int, replace bool) discord.InteractionResponse {
+	var quote model.Quote
+	var choices []model.Quote
+	var total int
+	var err error
+
+	if text, filters := search.ParseQuery(query); len(text) == 0 && len(filters) == 0 {
+		quote, err = s.random(ctx, indexName, channel)
+	} else if s.discordChoices > 1 {
+		choices, total, err = s.search.SearchHits(ctx, indexName, text, filters, 0, s.discordChoices)
+
+		if err == nil && offset < len(choices) {
+			quote = choices[offset]
+		} else if err == nil && len(choices) == s.discordChoices {
+			quote, total, err = s.search.Search(ctx, indexName, text, filters, offset)
+		}
+	} else {
+		quote, total, err = s.search.Search(ctx, indexName, text, filters, offset)
+	}
 
 	if err != nil && !errors.Is(err, search.ErrNotFound) {
 		if errors.Is(err, search.ErrIndexNotFound) {
-			return discord.NewEphemeral(offset != 0, "Tout doux bijou, le moteur de recherche était pété, je le redémarre.")
+			return discord.NewEphemeral(replace, indexingMessage(err))
+		}
+
+		if errors.Is(err, httpmodel.ErrInvalid) {
+			return discord.NewEphemeral(replace, invalidMessage(indexName, err))
 		}
 
 		slog.LogAttrs(ctx, slog.LevelError, "search", slog.String("index", indexName), slog.String("query", query), slog.Int("offset", offset), slog.Any("error", err))
-		return discord.NewEphemeral(offset != 0, fmt.Sprintf("Oh, it's broken 😱. Reason: %s", err))
+		return discord.NewEphemeral(replace, fmt.Sprintf("Oh, it's broken 😱. Reason: %s", err))
 	}
 
 	if len(quote.ID) == 0 {
-		return discord.NewEphemeral(offset != 0, fmt.Sprintf("We found nothing for `%s`", query))
+		return discord.NewEphemeral(replace, fmt.Sprintf("We found nothing for `%s`", query))
 	}
 
-	return s.interactiveResponse(ctx, indexName, quote, query, offset)
+	return s.interactiveResponse(ctx, indexName, quote, query, offset, total, replace, choices)
 }
 
-func (s Service) interactiveResponse(ctx context.Context, indexName string, quote model.Quote, search string, offset int) discord.InteractionResponse {
+// interactiveResponse renders the quote as a preview with navigation buttons. A zero `total` means the quote is random.
+func (s Service) interactiveResponse(ctx context.Context, indexName string, quote model.Quote, search string, offset, total int, replace bool, choices []model.Quote) discord.InteractionResponse {
 	var err error
 
 	ctx, end := telemetry.StartSpan(ctx, s.tracer, "interactiveResponse")
 	defer end(&err)
 
-	replace := offset != 0
-
 	webhookType := discord.ChannelMessageWithSource
 	if replace {
 		webhookType = discord.UpdateMessageCallback
@@ -165,88 +210,196 @@ func (s Service) interactiveResponse(ctx context.Context, indexName string, quot
 		return discord.NewError(replace, err)
 	}
 
-	nextValues := url.Values{}
-	nextValues.Add("action", nextValue)
-	nextValues.Add("offset", strconv.Itoa(offset+1))
-	nextValues.Add("search", search)
+	buttons := []discord.Component{discord.NewButton(discord.PrimaryButton, i18n[sendValue], sendKey)}
+	embed := s.getQuoteEmbed(indexName, quote)
+	hasPrevious, hasNext := navigation(offset, total)
+
+	if total != 0 {
+		embed.Footer = &discord.Footer{Text: position(offset, total)}
 
-	nextKey, err := discord.SaveCustomID(ctx, s.redisClient, cachePrefix, nextValues)
+		previousButton, err := s.navigationButton(ctx, previousValue, search, offset-1)
+		if err != nil {
+			return discord.NewError(replace, err)
+		}
+
+		previousButton.Disabled = !hasPrevious
+		buttons = append(buttons, previousButton)
+	}
+
+	nextButton, err := s.navigationButton(ctx, nextValue, search, offset+1)
 	if err != nil {
 		return discord.NewError(replace, err)
 	}
 
-	return discord.NewResponse(webhookType, "").Ephemeral().AddEmbed(s.getQuoteEmbed(indexName, quote)).AddComponent(
+	nextButton.Disabled = !hasNext
+
+	response := discord.NewResponse(webhookType, "").Ephemeral().AddEmbed(embed).AddComponent(
 		discord.Component{
-			Type: discord.ActionRowType,
-			Components: []discord.Component{
-				discord.NewButton(discord.PrimaryButton, i18n[sendValue], sendKey),
-				discord.NewButton(discord.SecondaryButton, i18n[nextValue], nextKey),
-				discord.NewButton(discord.DangerButton, i18n[cancelValue], cancelAction),
-			},
+			Type:       discord.ActionRowType,
+			Components: append(buttons, nextButton, discord.NewButton(discord.DangerButton, i18n[cancelValue], cancelAction)),
 		})
+
+	if len(choices) < 2 {
+		return response
+	}
+
+	pickValues := url.Values{}
+	pickValues.Add("action", pickValue)
+	pickValues.Add("search", search)
+
+	pickKey, err := discord.SaveCustomID(ctx, s.redisClient, cachePrefix, pickValues)
+	if err != nil {
+		return discord.NewError(replace, err)
+	}
+
+	return response.AddComponent(discord.Component{
+		Type: discord.ActionRowType,
+		Components: []discord.Component{
+			{
+				Type:     discord.SelectMenuType,
+				CustomID: pickKey,
+				Options:  selectOptions(choices),
+			},
+		},
+	})
 }
 
-func (s Service) quoteResponse(user, indexName string, quote model.Quote) (discord.InteractionResponse, bool, func(context.Context) discord.InteractionResponse) {
-	return discord.NewReplace("Sending it..."), true, func(ctx context.Context) discord.InteractionResponse {
-		return discord.NewResponse(discord.ChannelMessageWithSource, fmt.Sprintf("<@!%s> %s", user, i18n["title"])).AddEmbed(s.getQuoteEmbed(indexName, quote))
+func (s Service) navigationButton(ctx context.Context, action, search string, offset int) (discord.Component, error) {
+	values := url.Values{}
+	values.Add("action", action)
+	values.Add("offset", strconv.Itoa(max(offset, 0)))
+	values.Add("search", search)
+
+	key, err := discord.SaveCustomID(ctx, s.redisClient, cachePrefix, values)
+	if err != nil {
+		return discord.Component{}, err
 	}
+
+	return discord.NewButton(discord.SecondaryButton, i18n[action], key), nil
 }
 
-func (s Service) getQuoteEmbed(indexName string, quote model.Quote) discord.Embed {
-	switch indexName {
-	case kaamelottName:
-		return s.getKaamelottEmbeds(quote)
+func selectOptions(quotes []model.Quote) []discord.SelectOption {
+	output := make([]discord.SelectOption, len(quotes))
 
-	case oss117Name:
-		return s.getOss117Embeds(quote)
+	for i, quote := range quotes {
+		output[i] = discord.SelectOption{
+			Label:       optionLabel(quote),
+			Value:       strconv.Itoa(i),
+			Description: truncate(quote.Character, maxSelectOptionLen),
+		}
+	}
 
-	case abitbolName:
-		return s.getAbitbolEmbeds(quote)
+	return output
+}
 
-	default:
-		return discord.Embed{
-			Title:       "Error",
-			Description: fmt.Sprintf("render quote of index `%s`", indexName),
+// optionLabel names a quote in a select menu or an autocomplete, where Discord rejects an empty label: some quotes are only an image with a context.
+func optionLabel(quote model.Quote) string {
+	for _, label := range []string{quote.Value, quote.Context, quote.ID} {
+		if label = truncate(label, maxSelectOptionLen); len(label) != 0 {
+			return label
 		}
 	}
+
+	return ""
 }
 
-func (s Service) getKaamelottEmbeds(quote model.Quote) discord.Embed {
-	var thumbnail, image *discord.Image
-	if len(quote.Image) != 0 {
-		image = discord.NewImage(quote.Image)
-	} else {
-		thumbnail = discord.NewImage(fmt.Sprintf("%s/images/kaamelott.png", s.website))
+// truncate shortens `content` to `size` runes at most, ending with an ellipsis when cut.
+func truncate(content string, size int) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= size {
+		return string(runes)
 	}
 
-	return discord.Embed{
-		Title:       quote.Context,
-		Description: quote.Value,
-		URL:         quote.URL,
-		Image:       image,
-		Thumbnail:   thumbnail,
-		Fields: []discord.Field{
-			discord.NewField("Personnage", quote.Character),
-		},
+	return strings.TrimSpace(string(runes[:size-1])) + "…"
+}
+
+func (s Service) quoteResponse(user, indexName string, quote model.Quote) (discord.InteractionResponse, bool, func(context.Context) discord.InteractionResponse) {
+	return discord.NewReplace("Sending it..."), true, func(ctx context.Context) discord.InteractionResponse {
+		return s.quoteMessage(user, indexName, quote)
 	}
 }
 
-func (s Service) getOss117Embeds(quote model.Quote) discord.Embed {
-	return discord.Embed{
-		Title:       quote.Context,
-		Description: quote.Value,
-		Thumbnail:   discord.NewImage(fmt.Sprintf("%s/images/oss117.png", s.website)),
-		Fields: []discord.Field{
-			discord.NewField("Personnage", quote.Character),
-		},
+func (s Service) quoteMessage(user, indexName string, quote model.Quote) discord.InteractionResponse {
+	return discord.NewResponse(discord.ChannelMessageWithSource, fmt.Sprintf("<@!%s> %s", user, i18n["title"])).AddEmbed(s.getQuoteEmbed(indexName, quote))
+}
+
+// autocomplete proposes the best quotes for the focused `recherche` option, picking one posts it by its ID.
+func (s Service) autocomplete(ctx context.Context, indexName string, webhook discord.InteractionRequest) discord.InteractionResponse {
+	var err error
+
+	ctx, end := telemetry.StartSpan(ctx, s.tracer, "autocomplete")
+	defer end(&err)
+
+	response := discord.InteractionResponse{
+		Type: discord.ApplicationCommandAutocompleteResultCallback,
+		Data: discord.InteractionDataResponse{Choices: []discord.Choice{}},
+	}
+
+	var query string
+
+	for _, option := range webhook.Data.Options {
+		if option.Focused && strings.EqualFold(option.Name, queryParam) {
+			query = option.Value
+		}
+	}
+
+	text, filters := search.ParseQuery(query)
+	if len(text) == 0 && len(filters) == 0 {
+		return response
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, autocompleteTimeout)
+	defer cancel()
+
+	quotes, _, err := s.search.SearchHits(ctx, indexName, text, filters, 0, maxDiscordChoices)
+	if err != nil {
+		if !errors.Is(err, search.ErrIndexNotFound) && !errors.Is(err, httpmodel.ErrInvalid) {
+			slog.LogAttrs(ctx, slog.LevelError, "autocomplete", slog.String("index", indexName), slog.String("query", query), slog.Any("error", err))
+		}
+
+		return response
 	}
+
+	for _, quote := range quotes {
+		if len(idPrefix)+len(quote.ID) > maxSelectOptionLen {
+			continue
+		}
+
+		response.Data.Choices = append(response.Data.Choices, discord.Choice{
+			Name:  optionLabel(quote),
+			Value: idPrefix + quote.ID,
+		})
+	}
+
+	return response
 }
 
-func (s Service) getAbitbolEmbeds(quote model.Quote) discord.Embed {
-	return discord.Embed{
+func (s Service) getQuoteEmbed(indexName string, quote model.Quote) discord.Embed {
+	universe, ok := universe.Get(indexName)
+	if !ok {
+		return discord.Embed{
+			Title:       "Error",
+			Description: fmt.Sprintf("render quote of index `%s`", indexName),
+		}
+	}
+
+	embed := discord.Embed{
 		Title:       quote.Context,
 		Description: quote.Value,
-		URL:         quote.URL,
-		Thumbnail:   discord.NewImage(quote.Image),
+		URL:         s.getLink(universe, quote),
+	}
+
+	if len(quote.Image) != 0 && !universe.ImageAsThumbnail {
+		embed.Image = discord.NewImage(quote.Image)
+	} else if card := s.getCard(universe, quote); len(card) != 0 {
+		embed.Image = discord.NewImage(card)
+	} else if thumbnail := s.getThumbnail(universe, quote); len(thumbnail) != 0 {
+		embed.Thumbnail = discord.NewImage(thumbnail)
 	}
+
+	if universe.ShowCharacter && len(quote.Character) != 0 {
+		embed.Fields = append(embed.Fields, discord.NewField("Personnage", quote.Character))
+	}
+
+	return embed
 }
@@ -0,0 +1,162 @@
+package quote
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ViBiOh/ChatPotte/discord"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+)
+
+func TestOptionLabel(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		quote model.Quote
+		want  string
+	}{
+		"value": {
+			quote: model.Quote{ID: "cest_pas_faux", Value: "C'est pas faux.", Context: "Livre II"},
+			want:  "C'est pas faux.",
+		},
+		"context": {
+			quote: model.Quote{ID: "b62dc9d8", Value: " ", Context: "Meuh !!!"},
+			want:  "Meuh !!!",
+		},
+		"id": {
+			quote: model.Quote{ID: "b62dc9d8"},
+			want:  "b62dc9d8",
+		},
+		"truncated": {
+			quote: model.Quote{Value: strings.Repeat("a", maxSelectOptionLen+1)},
+			want:  strings.Repeat("a", maxSelectOptionLen-1) + "…",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := optionLabel(testCase.quote); got != testCase.want {
+				t.Errorf("optionLabel() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+func autocompleteRequest(query string, focused bool) discord.InteractionRequest {
+	return discord.InteractionRequest{
+		Type: discord.ApplicationCommandAutocompleteInteraction,
+		Data: discord.InteractionData{
+			Name:    "kaamelott",
+			Options: []discord.CommandOption{{Name: queryParam, Value: query, Focused: focused}},
+		},
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	t.Parallel()
+
+	service := newTestService(t, "")
+
+	cases := map[string]struct {
+		query   string
+		focused bool
+		wantMin int
+		wantMax int
+	}{
+		"matching": {
+			query:   "pas faux",
+			focused: true,
+			wantMin: 1,
+			wantMax: maxDiscordChoices,
+		},
+		"at most the Discord limit": {
+			query:   "perso:perceval",
+			focused: true,
+			wantMin: maxDiscordChoices,
+			wantMax: maxDiscordChoices,
+		},
+		"empty": {
+			query:   " ",
+			focused: true,
+		},
+		"not focused": {
+			query: "pas faux",
+		},
+		"invalid filter": {
+			query:   "livre:IIII",
+			focused: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			response := service.autocomplete(context.Background(), "kaamelott", autocompleteRequest(testCase.query, testCase.focused))
+
+			if response.Type != discord.ApplicationCommandAutocompleteResultCallback {
+				t.Errorf("autocomplete().Type = %d, want an autocomplete result", response.Type)
+			}
+
+			choices := response.Data.Choices
+			if choices == nil {
+				t.Error("autocomplete() has nil choices, Discord wants an array")
+			}
+
+			if len(choices) < testCase.wantMin || len(choices) > testCase.wantMax {
+				t.Errorf("autocomplete(`%s`) = %d choices, want from %d to %d", testCase.query, len(choices), testCase.wantMin, testCase.wantMax)
+			}
+
+			for _, choice := range choices {
+				if length := utf8.RuneCountInString(choice.Name); length == 0 || length > maxSelectOptionLen {
+					t.Errorf("choice `%s` is %d characters long", choice.Name, length)
+				}
+
+				if !strings.HasPrefix(choice.Value, idPrefix) || len(choice.Value) > maxSelectOptionLen {
+					t.Errorf("choice value `%s` is not a quote ID", choice.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestAutocompletePick(t *testing.T) {
+	t.Parallel()
+
+	service := newTestService(t, "")
+
+	choices := service.autocomplete(context.Background(), "kaamelott", autocompleteRequest("pas faux perso:perceval", true)).Data.Choices
+	if len(choices) == 0 {
+		t.Fatal("autocomplete() has no choice")
+	}
+
+	for _, choice := range choices {
+		action, id, offset, err := service.getQuery(context.Background(), discord.InteractionRequest{
+			Type: discord.ApplicationCommandInteraction,
+			Data: discord.InteractionData{
+				Name:    "kaamelott",
+				Options: []discord.CommandOption{{Name: queryParam, Value: choice.Value}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("getQuery() = %s", err)
+		}
+
+		if action != sendValue || offset != 0 || idPrefix+id != choice.Value {
+			t.Errorf("getQuery(`%s`) = %s `%s` at %d, want to send it", choice.Value, action, id, offset)
+		}
+
+		quote, err := service.search.GetByID(context.Background(), "kaamelott", id)
+		if err != nil {
+			t.Fatalf("GetByID(`%s`) = %s", id, err)
+		}
+
+		if got := optionLabel(quote); got != choice.Name {
+			t.Errorf("picked `%s`, want `%s`", got, choice.Name)
+		}
+	}
+}
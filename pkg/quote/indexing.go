@@ -0,0 +1,21 @@
+package quote
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ViBiOh/kaamebott/pkg/search"
+)
+
+func indexingMessage(err error) string {
+	indexing, ok := errors.AsType[search.IndexingError](err)
+	if !ok {
+		return "Tout doux bijou, le moteur de recherche était pété, je le redémarre."
+	}
+
+	if indexing.Running {
+		return fmt.Sprintf("Tout doux bijou, le moteur de recherche est déjà en train de redémarrer, encore %s environ.", indexing.Remaining())
+	}
+
+	return fmt.Sprintf("Tout doux bijou, le moteur de recherche était pété, je le redémarre. Ça devrait prendre %s environ.", indexing.Remaining())
+}
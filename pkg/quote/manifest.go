@@ -0,0 +1,76 @@
+package quote
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+var manifestTemplate = template.Must(template.New("slack_manifest").Funcs(template.FuncMap{
+	"usageHint": usageHint,
+}).Parse(`---
+_metadata:
+  major_version: 1
+  minor_version: 1
+display_information:
+  name: {{ printf "%q" .Title }}
+  description: Get a Kaamelott quote
+  background_color: "#004492"
+features:
+  bot_user:
+    display_name: {{ printf "%q" .Title }}
+    always_online: false
+  slash_commands:
+{{- range .Universes }}
+    - command: /{{ .Name }}
+      url: {{ $.URL }}/slack/{{ .Name }}
+      description: {{ printf "%q" .Description }}
+      usage_hint: {{ printf "%q" (usageHint .) }}
+      should_escape: false
+{{- end }}
+oauth_config:
+  redirect_urls:
+    - {{ .URL }}/slack/oauth
+  scopes:
+    bot:
+      - commands
+settings:
+  interactivity:
+    is_enabled: true
+    request_url: {{ .URL }}/slack/interactive
+  org_deploy_enabled: false
+  socket_mode_enabled: false
+  token_rotation_enabled: false
+`))
+
+// SlackManifest writes the Slack app manifest declaring a command for every known universe, served from the given public URL.
+func SlackManifest(w io.Writer, publicURL, title string) error {
+	if err := manifestTemplate.Execute(w, map[string]any{
+		"Title":     title,
+		"URL":       strings.TrimSuffix(publicURL, "/"),
+		"Universes": universe.All(),
+	}); err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+
+	return nil
+}
+
+func usageHint(item universe.Universe) string {
+	hints := []string{"[searched text]"}
+
+	if item.ShowCharacter {
+		hints = append(hints, "[perso:name]")
+	}
+
+	hints = append(hints, `[episode:"name"]`)
+
+	if item.Books {
+		hints = append(hints, "[livre:number]")
+	}
+
+	return strings.Join(hints, " ")
+}
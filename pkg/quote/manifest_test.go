@@ -0,0 +1,62 @@
+package quote
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+func TestSlackManifest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("committed one", func(t *testing.T) {
+		t.Parallel()
+
+		want, err := os.ReadFile("../../slack_manifest.yaml")
+		if err != nil {
+			t.Fatalf("read manifest: %s", err)
+		}
+
+		var got strings.Builder
+		if err := SlackManifest(&got, "https://kaamebott.vibioh.fr", "Kaamebott"); err != nil {
+			t.Fatalf("SlackManifest() = %s", err)
+		}
+
+		if got.String() != string(want) {
+			t.Errorf("slack_manifest.yaml is outdated, run `make slack-manifest`:\n%s", got.String())
+		}
+	})
+
+	t.Run("self-hosted", func(t *testing.T) {
+		t.Parallel()
+
+		var builder strings.Builder
+		if err := SlackManifest(&builder, "https://bot.example.com/", `Le "Bot"`); err != nil {
+			t.Fatalf("SlackManifest() = %s", err)
+		}
+
+		got := builder.String()
+
+		want := []string{
+			`  name: "Le \"Bot\""`,
+			"    - https://bot.example.com/slack/oauth",
+			"    request_url: https://bot.example.com/slack/interactive",
+		}
+
+		for _, item := range universe.All() {
+			want = append(want, "    - command: /"+item.Name, "      url: https://bot.example.com/slack/"+item.Name)
+		}
+
+		for _, line := range want {
+			if !strings.Contains(got, line+"\n") {
+				t.Errorf("SlackManifest() has no `%s` line:\n%s", line, got)
+			}
+		}
+
+		if strings.Contains(got, "kaamebott.vibioh.fr") {
+			t.Errorf("SlackManifest() references the default URL:\n%s", got)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package quote
+
+import (
+	"cmp"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/search"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+const (
+	// APIPath is the path the API is served from.
+	APIPath        = "/api"
+	apiVersion     = "v1"
+	schemasPrefix  = "#/components/schemas/"
+	openAPIVersion = "3.1.0"
+)
+
+// apiRoute describes a GET route in the OpenAPI specification. Without content type, it responds JSON, a free-form object without response type.
+type apiRoute struct {
+	response    reflect.Type
+	path        string
+	operation   string
+	summary     string
+	contentType string
+	parameters  []apiParameter
+}
+
+type apiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description"`
+	Schema      map[string]any `json:"schema"`
+	Required    bool           `json:"required"`
+}
+
+var (
+	universeParameter = apiParameter{Name: "universe", In: "path", Description: "Name of the universe", Required: true, Schema: map[string]any{"type": "string"}}
+	idParameter       = apiParameter{Name: "id", In: "path", Description: "ID of the quote", Required: true, Schema: map[string]any{"type": "string"}}
+
+	apiRoutes = []apiRoute{
+		{
+			path:    "/openapi.json",
+			summary: "Get the OpenAPI specification of this API",
+		},
+		{
+			path:     "/v1/universes",
+			summary:  "List the universes",
+			response: reflect.TypeFor[universesPage](),
+		},
+		{
+			path:    "/v1/{universe}/quotes",
+			summary: "Search quotes, with the same filters as the commands",
+			parameters: []apiParameter{
+				universeParameter,
+				{Name: "q", In: "query", Description: "Searched text and filters, e.g. `gras perso:karadoc`", Schema: map[string]any{"type": "string"}},
+				{Name: "offset", In: "query", Description: "Number of skipped quotes", Schema: map[string]any{"type": "integer", "minimum": 0, "default": 0}},
+				{Name: "limit", In: "query", Description: "Number of returned quotes", Schema: map[string]any{"type": "integer", "minimum": 1, "maximum": maxAPILimit, "default": defaultAPILimit}},
+			},
+			response: reflect.TypeFor[QuotesPage](),
+		},
+		{
+			path:       "/v1/{universe}/quotes/{id}",
+			summary:    "Get a quote by its ID",
+			parameters: []apiParameter{universeParameter, idParameter},
+			response:   reflect.TypeFor[model.Quote](),
+		},
+		{
+			path:       "/v1/{universe}/random",
+			summary:    "Get a random quote",
+			parameters: []apiParameter{universeParameter},
+			response:   reflect.TypeFor[model.Quote](),
+		},
+	}
+
+	// webRoutes are the pages of the website, served from its root rather than from APIPath.
+	webRoutes = []apiRoute{
+		{
+			path:        search.SearchPattern,
+			operation:   "getSearchPage",
+			summary:     "Search quotes on the website",
+			contentType: "text/html",
+			parameters: []apiParameter{
+				{Name: "universe", In: "query", Description: "Name of the searched universe, the home page without it", Schema: map[string]any{"type": "string"}},
+				{Name: "recherche", In: "query", Description: "Searched text and filters, e.g. `gras perso:karadoc`", Schema: map[string]any{"type": "string"}},
+				{Name: "perso", In: "query", Description: "Character of the quotes", Schema: map[string]any{"type": "string"}},
+				{Name: "offset", In: "query", Description: "Number of skipped quotes", Schema: map[string]any{"type": "integer", "minimum": 0, "default": 0}},
+			},
+		},
+		{
+			path:        search.QuotePattern,
+			operation:   "getQuotePage",
+			summary:     "Get the permalink page of a quote",
+			contentType: "text/html",
+			parameters:  []apiParameter{universeParameter, idParameter},
+		},
+		{
+			path:        search.CardPattern,
+			operation:   "getQuoteCard",
+			summary:     "Get the image card of a quote",
+			contentType: "image/png",
+			parameters:  []apiParameter{universeParameter, idParameter},
+		},
+	}
+
+	// apiSchemas are described once in the components, and referenced elsewhere.
+	apiSchemas = []reflect.Type{
+		reflect.TypeFor[model.Quote](),
+		reflect.TypeFor[universe.Universe](),
+		reflect.TypeFor[QuotesPage](),
+	}
+)
+
+// universesPage is the envelope of an array written by httpjson.WriteArray.
+type universesPage struct {
+	Items []universe.Universe `json:"items"`
+}
+
+func (s Service) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	httpjson.Write(r.Context(), w, http.StatusOK, OpenAPI(s.website))
+}
+
+// OpenAPI describes the API and the pages served from the given website, generated from their routes and Go types.
+func OpenAPI(website string) map[string]any {
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+		}
+	}
+
+	paths := make(map[string]any, len(apiRoutes)+len(webRoutes))
+
+	for _, route := range slices.Concat(apiRoutes, webRoutes) {
+		path, operation, content := route.path, route.operation, map[string]any{"schema": responseSchema(route.response)}
+
+		if len(route.contentType) == 0 {
+			path, operation = APIPath+route.path, operationID(route.path)
+		} else {
+			content = map[string]any{"schema": map[string]any{"type": "string"}}
+		}
+
+		responses := map[string]any{
+			"200": map[string]any{
+				"description": "OK",
+				"content":     map[string]any{cmp.Or(route.contentType, "application/json"): content},
+			},
+		}
+
+		for _, parameter := range route.parameters {
+			switch parameter.In {
+			case "path":
+				responses["404"] = errorResponse("Unknown universe or no quote found")
+			case "query":
+				responses["400"] = errorResponse("Invalid parameters or filters")
+			}
+		}
+
+		paths[path] = map[string]any{
+			"get": map[string]any{
+				"summary":     route.summary,
+				"operationId": operation,
+				"parameters":  append([]apiParameter{}, route.parameters...),
+				"responses":   responses,
+			},
+		}
+	}
+
+	schemas := make(map[string]any, len(apiSchemas))
+	for _, schema := range apiSchemas {
+		schemas[schema.Name()] = schemaOf(schema, false)
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "Kaamebott",
+			"version": apiVersion,
+		},
+		"servers":    []map[string]any{{"url": strings.TrimSuffix(website, "/")}},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func responseSchema(kind reflect.Type) map[string]any {
+	if kind == nil {
+		return map[string]any{"type": "object"}
+	}
+
+	return schemaOf(kind, true)
+}
+
+// schemaOf describes the JSON encoding of the given type, as a reference when it is one of the components and `reference` is set.
+func schemaOf(kind reflect.Type, reference bool) map[string]any {
+	switch kind.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaOf(kind.Elem(), true)}
+	case reflect.Struct:
+		if reference && slices.Contains(apiSchemas, kind) {
+			return map[string]any{"$ref": schemasPrefix + kind.Name()}
+		}
+
+		properties := make(map[string]any, kind.NumField())
+		var required []string
+
+		for i := range kind.NumField() {
+			field := kind.Field(i)
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+
+			if len(name) == 0 {
+				name = field.Name
+			}
+
+			properties[name] = schemaOf(field.Type, true)
+
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+
+		output := map[string]any{"type": "object", "properties": properties}
+		if len(required) != 0 {
+			output["required"] = required
+		}
+
+		return output
+	default:
+		return map[string]any{}
+	}
+}
+
+// operationID names an operation after its path, e.g. `getUniverseQuotesId` for `/v1/{universe}/quotes/{id}`.
+func operationID(path string) string {
+	var builder strings.Builder
+	builder.WriteString("get")
+
+	path = strings.TrimPrefix(path, "/"+apiVersion)
+
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return builder.String()
+}
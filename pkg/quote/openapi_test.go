@@ -0,0 +1,54 @@
+package quote
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	var router patternRecorder
+	Service{}.RegisterAPI(&router)
+
+	if len(router) == 0 {
+		t.Fatal("no route registered")
+	}
+
+	paths, ok := OpenAPI("https://kaamebott.vibioh.fr")["paths"].(map[string]any)
+	if !ok {
+		t.Fatal("no paths in the specification")
+	}
+
+	for _, pattern := range router {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			t.Errorf("route `%s` has no method", pattern)
+			continue
+		}
+
+		operations, ok := paths[APIPath+path].(map[string]any)
+		if !ok {
+			t.Errorf("route `%s` is not described", pattern)
+			continue
+		}
+
+		if _, ok := operations[strings.ToLower(method)]; !ok {
+			t.Errorf("route `%s` has no `%s` operation", pattern, strings.ToLower(method))
+		}
+	}
+
+	if want := len(router) + len(webRoutes); len(paths) != want {
+		t.Errorf("specification describes %d paths, want %d", len(paths), want)
+	}
+
+	operations := make(map[string]bool)
+	for path, item := range paths {
+		operation := item.(map[string]any)["get"].(map[string]any)["operationId"].(string)
+		if operations[operation] {
+			t.Errorf("operation `%s` of `%s` is not unique", operation, path)
+		}
+
+		operations[operation] = true
+	}
+}
@@ -0,0 +1,30 @@
+package quote
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+// position describes the rank of the result at `offset` among `total` ones.
+func position(offset, total int) string {
+	return fmt.Sprintf("%d / %d", offset+1, total)
+}
+
+// navigation tells if there are results before and after the one at `offset` among `total` ones. A zero `total` means the quote is random, always followed by another one.
+func navigation(offset, total int) (hasPrevious, hasNext bool) {
+	return total != 0 && offset > 0, total == 0 || offset+1 < total
+}
+
+// invalidMessage explains to the user why the filters of their search are rejected.
+func invalidMessage(indexName string, err error) string {
+	if errors.Is(err, indexer.ErrUnknownBook) {
+		return "Livre inconnu, c'est par exemple `livre:2` ou `livre:II`."
+	}
+
+	item, _ := universe.Get(indexName)
+
+	return fmt.Sprintf("Je n'ai pas compris la recherche, c'est de la forme `%s`.", usageHint(item))
+}
@@ -0,0 +1,96 @@
+package quote
+
+import (
+	"fmt"
+	"testing"
+
+	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+)
+
+func TestNavigation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		offset       int
+		total        int
+		wantPosition string
+		wantPrevious bool
+		wantNext     bool
+	}{
+		"first": {
+			offset:       0,
+			total:        42,
+			wantPosition: "1 / 42",
+			wantNext:     true,
+		},
+		"middle": {
+			offset:       2,
+			total:        42,
+			wantPosition: "3 / 42",
+			wantPrevious: true,
+			wantNext:     true,
+		},
+		"last": {
+			offset:       41,
+			total:        42,
+			wantPosition: "42 / 42",
+			wantPrevious: true,
+		},
+		"only one": {
+			offset:       0,
+			total:        1,
+			wantPosition: "1 / 1",
+		},
+		"random": {
+			offset:   0,
+			total:    0,
+			wantNext: true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if testCase.total != 0 {
+				if got := position(testCase.offset, testCase.total); got != testCase.wantPosition {
+					t.Errorf("position() = `%s`, want `%s`", got, testCase.wantPosition)
+				}
+			}
+
+			hasPrevious, hasNext := navigation(testCase.offset, testCase.total)
+			if hasPrevious != testCase.wantPrevious || hasNext != testCase.wantNext {
+				t.Errorf("navigation(%d, %d) = %t, %t, want %t, %t", testCase.offset, testCase.total, hasPrevious, hasNext, testCase.wantPrevious, testCase.wantNext)
+			}
+		})
+	}
+}
+
+func TestInvalidMessage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"book": {
+			err:  fmt.Errorf("normalize filters: %w: %w", httpmodel.ErrInvalid, indexer.ErrUnknownBook),
+			want: "Livre inconnu, c'est par exemple `livre:2` ou `livre:II`.",
+		},
+		"other": {
+			err:  fmt.Errorf("normalize filters: %w", httpmodel.ErrInvalid),
+			want: "Je n'ai pas compris la recherche, c'est de la forme `[searched text] [perso:name] [episode:\"name\"] [livre:number]`.",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := invalidMessage("kaamelott", testCase.err); got != testCase.want {
+				t.Errorf("invalidMessage() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package quote
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/version"
+)
+
+const historyTTL = time.Hour * 24
+
+var historyPrefix = version.Redis("history")
+
+func (s Service) random(ctx context.Context, indexName, channel string) (model.Quote, error) {
+	if s.randomHistory <= 0 || len(channel) == 0 {
+		return s.search.Random(ctx, indexName, nil)
+	}
+
+	key := fmt.Sprintf("%s:%s:%s", historyPrefix, indexName, channel)
+
+	history, err := s.loadHistory(ctx, key)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "load random history", slog.String("index", indexName), slog.Any("error", err))
+	}
+
+	quote, err := s.search.Random(ctx, indexName, history)
+	if err != nil {
+		return quote, err
+	}
+
+	history = append(history, quote.ID)
+	if len(history) > s.randomHistory {
+		history = history[len(history)-s.randomHistory:]
+	}
+
+	if err := s.redisClient.Store(ctx, key, strings.Join(history, ","), historyTTL); err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "store random history", slog.String("index", indexName), slog.Any("error", err))
+	}
+
+	return quote, nil
+}
+
+func (s Service) loadHistory(ctx context.Context, key string) ([]string, error) {
+	content, err := s.redisClient.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) == 0 {
+		return nil, nil
+	}
+
+	return strings.Split(string(content), ","), nil
+}
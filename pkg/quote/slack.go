@@ -3,45 +3,73 @@ package quote
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"strings"
 
 	"github.com/ViBiOh/ChatPotte/slack"
+	"github.com/ViBiOh/flags"
 	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
 	"github.com/ViBiOh/httputils/v4/pkg/redis"
 	"github.com/ViBiOh/kaamebott/pkg/model"
 	"github.com/ViBiOh/kaamebott/pkg/search"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
 	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	cancelValue = "cancel"
-	nextValue   = "next"
-	sendValue   = "send"
+	cancelValue   = "cancel"
+	nextValue     = "next"
+	pickValue     = "pick"
+	previousValue = "previous"
+	sendValue     = "send"
 )
 
 var i18n map[string]string = map[string]string{
-	cancelValue: "Annuler",
-	nextValue:   "Une autre ?",
-	sendValue:   "Envoyer",
-	"not_found": "On n'a rien trouvé pour",
-	"title":     "Posté par ",
+	cancelValue:   "Annuler",
+	nextValue:     "Une autre ?",
+	previousValue: "Précédente",
+	sendValue:     "Envoyer",
+	"not_found":   "On n'a rien trouvé pour",
+	"title":       "Posté par ",
 }
 
 type Service struct {
-	search      search.Service
-	redisClient redis.Client
-	tracer      trace.Tracer
-	website     string
+	search         search.Service
+	redisClient    redis.Client
+	tracer         trace.Tracer
+	website        string
+	randomHistory  int
+	discordChoices int
+	cards          bool
 }
 
-func New(website string, searchService search.Service, redisClient redis.Client, tracerProvider trace.TracerProvider) Service {
+type Config struct {
+	RandomHistory  int
+	DiscordChoices int
+	Cards          bool
+}
+
+func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
+	var config Config
+
+	flags.New("RandomHistory", "Number of last random quotes not repeated in a channel").Prefix(prefix).DocPrefix("quote").IntVar(fs, &config.RandomHistory, 10, overrides)
+	flags.New("DiscordChoices", "Number of best results proposed in a Discord select menu, 0 to disable").Prefix(prefix).DocPrefix("quote").IntVar(fs, &config.DiscordChoices, 5, overrides)
+	flags.New("Cards", "Show the generated image card of quotes without image").Prefix(prefix).DocPrefix("quote").BoolVar(fs, &config.Cards, false, overrides)
+
+	return &config
+}
+
+func New(config *Config, website string, searchService search.Service, redisClient redis.Client, tracerProvider trace.TracerProvider) Service {
 	service := Service{
-		website:     website,
-		search:      searchService,
-		redisClient: redisClient,
+		website:        website,
+		search:         searchService,
+		redisClient:    redisClient,
+		randomHistory:  config.RandomHistory,
+		discordChoices: min(config.DiscordChoices, maxDiscordChoices),
+		cards:          config.Cards,
 	}
 
 	if tracerProvider != nil {
@@ -52,11 +80,11 @@ func New(website string, searchService search.Service, redisClient redis.Client,
 }
 
 func (s Service) SlackCommand(ctx context.Context, payload slack.SlashPayload) slack.Response {
-	if !s.search.HasIndex(ctx, payload.Command) {
+	if _, ok := universe.Get(payload.Command); !ok {
 		return slack.NewEphemeralMessage("unknown command")
 	}
 
-	return s.getQuoteBlock(ctx, payload.Command, payload.Text, 0)
+	return s.getQuoteBlock(ctx, payload.Command, payload.Text, payload.ChannelID, 0)
 }
 
 func (s Service) SlackInteract(ctx context.Context, payload slack.InteractivePayload) slack.Response {
@@ -75,10 +103,10 @@ func (s Service) SlackInteract(ctx context.Context, payload slack.InteractivePay
 			return slack.NewEphemeralMessage(fmt.Sprintf("find asked quote: %s", err))
 		}
 
-		return s.getQuoteResponse(action.BlockID, quote, "", payload.User.ID, 0)
+		return s.getQuoteResponse(action.BlockID, quote, "", payload.User.ID, 0, 0)
 	}
 
-	if action.ActionID == nextValue {
+	if action.ActionID == nextValue || action.ActionID == previousValue {
 		lastIndex := strings.LastIndexAny(action.Value, "@")
 		if lastIndex < 1 {
 			return slack.NewEphemeralMessage(fmt.Sprintf("button value seems wrong: %s", action.Value))
@@ -89,31 +117,47 @@ func (s Service) SlackInteract(ctx context.Context, payload slack.InteractivePay
 			return slack.NewEphemeralMessage("offset is not numeric")
 		}
 
-		return s.getQuoteBlock(ctx, action.BlockID, action.Value[:lastIndex], offset)
+		return s.getQuoteBlock(ctx, action.BlockID, action.Value[:lastIndex], payload.Container.ChannelID, offset)
 	}
 
 	return slack.NewEphemeralMessage("We don't understand what to do.")
 }
 
-func (s Service) getQuoteBlock(ctx context.Context, index, query string, offset int) slack.Response {
-	quote, err := s.search.Search(ctx, index, strings.TrimSpace(query), offset)
+func (s Service) getQuoteBlock(ctx context.Context, index, query, channel string, offset int) slack.Response {
+	var quote model.Quote
+	var total int
+	var err error
+
+	query = strings.TrimSpace(query)
+
+	if text, filters := search.ParseQuery(query); len(text) == 0 && len(filters) == 0 {
+		quote, err = s.random(ctx, index, channel)
+	} else {
+		quote, total, err = s.search.Search(ctx, index, text, filters, offset)
+	}
+
 	if err != nil {
 		if errors.Is(err, search.ErrNotFound) {
 			return slack.NewEphemeralMessage(fmt.Sprintf("We found nothing for `%s`", query))
 		}
 
 		if errors.Is(err, search.ErrIndexNotFound) {
-			return slack.NewEphemeralMessage("Tout doux bijou, le moteur de recherche était pété, je le redémarre.")
+			return slack.NewEphemeralMessage(indexingMessage(err))
+		}
+
+		if errors.Is(err, httpmodel.ErrInvalid) {
+			return slack.NewEphemeralMessage(invalidMessage(index, err))
 		}
 
 		slog.LogAttrs(ctx, slog.LevelError, "search error", slog.String("index", index), slog.String("query", query), slog.Int("offset", offset), slog.Any("error", err))
 		return slack.NewError(err)
 	}
 
-	return s.getQuoteResponse(index, quote, query, "", offset)
+	return s.getQuoteResponse(index, quote, query, "", offset, total)
 }
 
-func (s Service) getQuoteResponse(index string, quote model.Quote, query, user string, offset int) slack.Response {
+// getQuoteResponse renders the quote, as a preview with navigation buttons if no user sent it. A zero `total` means the quote is random.
+func (s Service) getQuoteResponse(index string, quote model.Quote, query, user string, offset, total int) slack.Response {
 	content := s.getContentBlock(index, quote)
 	if httpmodel.IsNil(content) {
 		return slack.NewEphemeralMessage(fmt.Sprintf("%s `%s`", i18n["not_found"], query))
@@ -129,7 +173,22 @@ func (s Service) getQuoteResponse(index string, quote model.Quote, query, user s
 			response = response.AddBlock(block)
 		}
 
-		return response.AddBlock(slack.NewActions(index, slack.NewButtonElement(i18n[cancelValue], cancelValue, "", "danger"), slack.NewButtonElement(i18n[nextValue], nextValue, fmt.Sprintf("%s@%d", query, offset+1), ""), slack.NewButtonElement(i18n[sendValue], sendValue, quote.ID, "primary")))
+		if total != 0 {
+			response = response.AddBlock(slack.NewContext().AddElement(slack.NewText(position(offset, total))))
+		}
+
+		buttons := []slack.Element{slack.NewButtonElement(i18n[cancelValue], cancelValue, "", "danger")}
+		hasPrevious, hasNext := navigation(offset, total)
+
+		if hasPrevious {
+			buttons = append(buttons, slack.NewButtonElement(i18n[previousValue], previousValue, fmt.Sprintf("%s@%d", query, offset-1), ""))
+		}
+
+		if hasNext {
+			buttons = append(buttons, slack.NewButtonElement(i18n[nextValue], nextValue, fmt.Sprintf("%s@%d", query, offset+1), ""))
+		}
+
+		return response.AddBlock(slack.NewActions(index, append(buttons, slack.NewButtonElement(i18n[sendValue], sendValue, quote.ID, "primary"))...))
 	}
 
 	response := slack.NewResponse("").WithDeleteOriginal()
@@ -141,29 +200,18 @@ func (s Service) getQuoteResponse(index string, quote model.Quote, query, user s
 }
 
 func (s Service) getContentBlock(indexName string, quote model.Quote) []slack.Block {
-	switch indexName {
-	case "kaamelott":
-		return s.getKaamelottBlock(quote)
-
-	case "oss117":
-		return []slack.Block{s.getOss117Block(quote)}
-
-	case "abitbol":
-		return []slack.Block{s.getAbitbolBlock(quote)}
-
-	default:
+	universe, ok := universe.Get(indexName)
+	if !ok {
 		return nil
 	}
-}
 
-func (s Service) getKaamelottBlock(quote model.Quote) []slack.Block {
 	var text string
 
-	if len(quote.URL) != 0 && len(quote.Context) != 0 {
-		text = fmt.Sprintf("*<%s|%s>*", quote.URL, quote.Context)
+	if len(quote.Context) != 0 {
+		text = fmt.Sprintf("*<%s|%s>*", s.getLink(universe, quote), quote.Context)
 	}
 
-	if len(quote.Character) != 0 {
+	if universe.ShowCharacter && len(quote.Character) != 0 {
 		if len(text) != 0 {
 			text += "\n\n"
 		}
@@ -180,29 +228,48 @@ func (s Service) getKaamelottBlock(quote model.Quote) []slack.Block {
 	}
 
 	section := slack.NewSection(slack.NewText(text))
-	var sections []slack.Block
 
-	if len(quote.Image) == 0 {
-		section = section.WithAccessory(slack.NewAccessory(fmt.Sprintf("%s/images/kaamelott.png", s.website), "kaamelott"))
-		sections = append(sections, section)
-	} else {
-		sections = append(sections, section)
-		sections = append(sections, slack.NewImage(quote.Image, quote.Value, quote.Character))
+	if len(quote.Image) != 0 && !universe.ImageAsThumbnail {
+		return []slack.Block{section, slack.NewImage(quote.Image, quote.Value, quote.Character)}
+	}
+
+	if card := s.getCard(universe, quote); len(card) != 0 {
+		return []slack.Block{section, slack.NewImage(card, quote.Value, quote.Character)}
+	}
+
+	if thumbnail := s.getThumbnail(universe, quote); len(thumbnail) != 0 {
+		section = section.WithAccessory(slack.NewAccessory(thumbnail, universe.Name))
 	}
 
-	return sections
+	return []slack.Block{section}
 }
 
-func (s Service) getOss117Block(quote model.Quote) slack.Block {
-	text := slack.NewText(fmt.Sprintf("*%s*\n\n_%s_ %s", quote.Context, quote.Character, quote.Value))
-	accessory := slack.NewAccessory(fmt.Sprintf("%s/images/oss117.png", s.website), "oss117")
+// getLink is the upstream page of the quote when the universe links it, its permalink otherwise.
+func (s Service) getLink(universe universe.Universe, quote model.Quote) string {
+	if universe.LinkContext && len(quote.URL) != 0 {
+		return quote.URL
+	}
 
-	return slack.NewSection(text).WithAccessory(accessory)
+	return s.website + search.QuotePath(universe.Name, quote.ID)
 }
 
-func (s Service) getAbitbolBlock(quote model.Quote) slack.Block {
-	text := slack.NewText(fmt.Sprintf("*<%s|%s>*\n\n_%s_ %s", quote.URL, quote.Context, quote.Character, quote.Value))
-	accessory := slack.NewAccessory(quote.Image, "abitbol")
+// getCard is the generated image card of a quote without image, when enabled.
+func (s Service) getCard(universe universe.Universe, quote model.Quote) string {
+	if !s.cards || len(quote.Image) != 0 {
+		return ""
+	}
+
+	return s.website + search.CardPath(universe, quote)
+}
+
+func (s Service) getThumbnail(universe universe.Universe, quote model.Quote) string {
+	if universe.ImageAsThumbnail && len(quote.Image) != 0 {
+		return quote.Image
+	}
+
+	if len(universe.Thumbnail) == 0 || strings.HasPrefix(universe.Thumbnail, "http") {
+		return universe.Thumbnail
+	}
 
-	return slack.NewSection(text).WithAccessory(accessory)
+	return s.website + universe.Thumbnail
 }
@@ -0,0 +1,205 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const randomAttempts = 5
+
+var episodeOrder = []string{"book:asc", "episode:asc"}
+
+var _ Backend = Meilisearch{}
+
+type Meilisearch struct {
+	client     meilisearch.ServiceManager
+	redis      redis.Client
+	reindexing *sync.Map
+	indexer    indexer.Service
+}
+
+func NewMeilisearch(url string, indexerService indexer.Service, redisClient redis.Client) Meilisearch {
+	return Meilisearch{
+		client:     meilisearch.New(url),
+		indexer:    indexerService,
+		redis:      redisClient,
+		reindexing: &sync.Map{},
+	}
+}
+
+func (m Meilisearch) HasIndex(ctx context.Context, indexName string) bool {
+	index, err := m.client.GetIndex(indexName)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, fmt.Sprintf("check if index `%s` exists", indexName), slog.Any("error", err))
+	}
+
+	return index != nil
+}
+
+func (m Meilisearch) GetByID(ctx context.Context, indexName, id string) (model.Quote, error) {
+	index, err := m.getIndex(ctx, indexName)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	var output model.Quote
+
+	err = index.GetDocument(id, &meilisearch.DocumentQuery{}, &output)
+	if meiliError, ok := errors.AsType[*meilisearch.Error](err); ok && meiliError.StatusCode == http.StatusNotFound {
+		quote, _, err := m.Search(ctx, indexName, "", []Filter{{Field: aliasFilter, Value: id}}, 0)
+
+		return quote, err
+	}
+
+	return output, err
+}
+
+func (m Meilisearch) Search(ctx context.Context, indexName, query string, filters []Filter, offset int) (model.Quote, int, error) {
+	index, err := m.getIndex(ctx, indexName)
+	if err != nil {
+		return model.Quote{}, 0, err
+	}
+
+	request := &meilisearch.SearchRequest{Limit: 1, Offset: int64(offset)}
+	if len(filters) != 0 {
+		request.Filter = filterExpression(filters)
+	}
+
+	if len(strings.TrimSpace(query)) == 0 {
+		request.Sort = episodeOrder
+	}
+
+	results, err := index.Search(query, request)
+	if err != nil {
+		return model.Quote{}, 0, err
+	}
+
+	total := int(results.EstimatedTotalHits)
+
+	if len(results.Hits) == 0 {
+		return model.Quote{}, total, ErrNotFound
+	}
+
+	var output model.Quote
+
+	var content map[string]any
+	if err := results.Hits[0].DecodeInto(&content); err != nil {
+		return model.Quote{}, total, err
+	}
+
+	return output, total, index.GetDocument(content["id"].(string), &meilisearch.DocumentQuery{}, &output)
+}
+
+func (m Meilisearch) SearchHits(ctx context.Context, indexName, query string, filters []Filter, offset, limit int) ([]model.Quote, int, error) {
+	index, err := m.getIndex(ctx, indexName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	request := &meilisearch.SearchRequest{Limit: int64(limit), Offset: int64(offset)}
+	if len(filters) != 0 {
+		request.Filter = filterExpression(filters)
+	}
+
+	if len(strings.TrimSpace(query)) == 0 {
+		request.Sort = episodeOrder
+	}
+
+	results, err := index.SearchWithContext(ctx, query, request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	output := make([]model.Quote, len(results.Hits))
+
+	for i, hit := range results.Hits {
+		if err := hit.DecodeInto(&output[i]); err != nil {
+			return nil, 0, fmt.Errorf("decode hit: %w", err)
+		}
+	}
+
+	return output, int(results.EstimatedTotalHits), nil
+}
+
+func (m Meilisearch) Random(ctx context.Context, indexName string, excluded []string) (model.Quote, error) {
+	index, err := m.getIndex(ctx, indexName)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	stats, err := index.GetStatsWithContext(ctx)
+	if err != nil {
+		return model.Quote{}, fmt.Errorf("get stats: %w", err)
+	}
+
+	if stats.NumberOfDocuments == 0 {
+		return model.Quote{}, ErrNotFound
+	}
+
+	var output model.Quote
+
+	for range randomAttempts {
+		var results meilisearch.DocumentsResult
+
+		if err := index.GetDocumentsWithContext(ctx, &meilisearch.DocumentsQuery{Limit: 1, Offset: rand.Int64N(stats.NumberOfDocuments)}, &results); err != nil {
+			return model.Quote{}, fmt.Errorf("get documents: %w", err)
+		}
+
+		if len(results.Results) == 0 {
+			continue
+		}
+
+		if err := results.Results[0].DecodeInto(&output); err != nil {
+			return model.Quote{}, fmt.Errorf("decode: %w", err)
+		}
+
+		if int64(len(excluded)) >= stats.NumberOfDocuments || !slices.Contains(excluded, output.ID) {
+			return output, nil
+		}
+	}
+
+	if len(output.ID) == 0 {
+		return model.Quote{}, ErrNotFound
+	}
+
+	return output, nil
+}
+
+func filterExpression(filters []Filter) string {
+	expressions := make([]string, len(filters))
+
+	for i, filter := range filters {
+		expressions[i] = fmt.Sprintf("%s = %q", filter.Field, filter.Value)
+	}
+
+	return strings.Join(expressions, " AND ")
+}
+
+func (m Meilisearch) Index(ctx context.Context, indexName string) error {
+	return m.indexer.Index(ctx, m.client, indexName)
+}
+
+func (m Meilisearch) getIndex(ctx context.Context, indexName string) (meilisearch.IndexManager, error) {
+	index, err := m.client.GetIndex(indexName)
+	if err != nil {
+		if meiliError, ok := errors.AsType[*meilisearch.Error](err); ok && meiliError.StatusCode == http.StatusNotFound {
+			return nil, m.reindex(ctx, indexName)
+		}
+
+		return nil, fmt.Errorf("get index: %w", err)
+	}
+
+	return index, nil
+}
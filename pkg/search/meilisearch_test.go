@@ -0,0 +1,36 @@
+package search
+
+import "testing"
+
+func TestFilterExpression(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		filters []Filter
+		want    string
+	}{
+		"none": {},
+		"one": {
+			filters: []Filter{{Field: CharacterFilter, Value: "perceval"}},
+			want:    `characterKeys = "perceval"`,
+		},
+		"all of them": {
+			filters: []Filter{{Field: CharacterFilter, Value: "perceval"}, {Field: ContextFilter, Value: "livreii"}, {Field: BookFilter, Value: "2"}},
+			want:    `characterKeys = "perceval" AND contextKeys = "livreii" AND book = "2"`,
+		},
+		"escaped": {
+			filters: []Filter{{Field: ContextFilter, Value: `le "code"`}},
+			want:    `contextKeys = "le \"code\""`,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := filterExpression(testCase.filters); got != testCase.want {
+				t.Errorf("filterExpression() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
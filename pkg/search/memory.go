@@ -0,0 +1,321 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+var _ Backend = &Memory{}
+
+type Memory struct {
+	indexes map[string]memoryIndex
+	indexer indexer.Service
+	mutex   sync.RWMutex
+}
+
+type memoryIndex struct {
+	byID      map[string]int
+	documents []indexer.Document
+	words     [][]string
+}
+
+type memoryHit struct {
+	position int
+	score    int
+}
+
+func NewMemory(ctx context.Context, indexerService indexer.Service) (*Memory, error) {
+	universes := universe.All()
+
+	memory := &Memory{
+		indexes: make(map[string]memoryIndex, len(universes)),
+		indexer: indexerService,
+	}
+
+	for _, universe := range universes {
+		if err := memory.Index(ctx, universe.Name); err != nil {
+			return nil, fmt.Errorf("index `%s`: %w", universe.Name, err)
+		}
+	}
+
+	return memory, nil
+}
+
+func (m *Memory) HasIndex(_ context.Context, indexName string) bool {
+	_, ok := m.getIndex(indexName)
+
+	return ok
+}
+
+func (m *Memory) GetByID(_ context.Context, indexName, id string) (model.Quote, error) {
+	index, ok := m.getIndex(indexName)
+	if !ok {
+		return model.Quote{}, ErrIndexNotFound
+	}
+
+	position, ok := index.byID[id]
+	if !ok {
+		return model.Quote{}, ErrNotFound
+	}
+
+	return index.documents[position].Quote, nil
+}
+
+func (m *Memory) Search(_ context.Context, indexName, query string, filters []Filter, offset int) (model.Quote, int, error) {
+	index, ok := m.getIndex(indexName)
+	if !ok {
+		return model.Quote{}, 0, ErrIndexNotFound
+	}
+
+	hits, err := index.search(query, filters)
+	if err != nil {
+		return model.Quote{}, 0, err
+	}
+
+	if offset < 0 || offset >= len(hits) {
+		return model.Quote{}, len(hits), ErrNotFound
+	}
+
+	return index.documents[hits[offset].position].Quote, len(hits), nil
+}
+
+func (m *Memory) SearchHits(_ context.Context, indexName, query string, filters []Filter, offset, limit int) ([]model.Quote, int, error) {
+	index, ok := m.getIndex(indexName)
+	if !ok {
+		return nil, 0, ErrIndexNotFound
+	}
+
+	hits, err := index.search(query, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := hits[min(max(offset, 0), len(hits)):]
+	page = page[:min(limit, len(page))]
+
+	output := make([]model.Quote, 0, len(page))
+	for _, hit := range page {
+		output = append(output, index.documents[hit.position].Quote)
+	}
+
+	return output, len(hits), nil
+}
+
+func (m *Memory) Random(_ context.Context, indexName string, excluded []string) (model.Quote, error) {
+	index, ok := m.getIndex(indexName)
+	if !ok {
+		return model.Quote{}, ErrIndexNotFound
+	}
+
+	candidates := make([]int, 0, len(index.documents))
+	for i, document := range index.documents {
+		if !slices.Contains(excluded, document.ID) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if len(index.documents) == 0 {
+			return model.Quote{}, ErrNotFound
+		}
+
+		return index.documents[rand.IntN(len(index.documents))].Quote, nil
+	}
+
+	return index.documents[candidates[rand.IntN(len(candidates))]].Quote, nil
+}
+
+func (m *Memory) Index(ctx context.Context, indexName string) error {
+	documents, err := m.indexer.Load(ctx, indexName)
+	if err != nil {
+		return err
+	}
+
+	index, err := newMemoryIndex(documents)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.indexes[indexName] = index
+
+	return nil
+}
+
+func newMemoryIndex(documents []indexer.Document) (memoryIndex, error) {
+	index := memoryIndex{
+		documents: documents,
+		byID:      make(map[string]int, len(documents)),
+		words:     make([][]string, len(documents)),
+	}
+
+	for i, document := range documents {
+		index.byID[document.ID] = i
+
+		for _, alias := range document.Aliases {
+			if _, ok := index.byID[alias]; !ok {
+				index.byID[alias] = i
+			}
+		}
+
+		words, err := sanitizeWords(document.Value + " " + document.Character + " " + document.Context)
+		if err != nil {
+			return memoryIndex{}, fmt.Errorf("sanitize quote `%s`: %w", document.ID, err)
+		}
+
+		index.words[i] = words
+	}
+
+	return index, nil
+}
+
+func (m *Memory) getIndex(indexName string) (memoryIndex, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	index, ok := m.indexes[indexName]
+
+	return index, ok
+}
+
+func (mi memoryIndex) search(query string, filters []Filter) ([]memoryHit, error) {
+	terms, err := sanitizeWords(query)
+	if err != nil {
+		return nil, fmt.Errorf("sanitize query: %w", err)
+	}
+
+	var hits []memoryHit
+
+	for i, words := range mi.words {
+		if !matches(mi.documents[i], filters) {
+			continue
+		}
+
+		var score int
+
+		for _, term := range terms {
+			termScore := matchScore(term, words)
+			if termScore == 0 {
+				score = 0
+				break
+			}
+
+			score += termScore
+		}
+
+		if score != 0 || len(terms) == 0 {
+			hits = append(hits, memoryHit{position: i, score: score})
+		}
+	}
+
+	if len(terms) == 0 {
+		slices.SortStableFunc(hits, func(a, b memoryHit) int {
+			return compareEpisodes(mi.documents[a.position].Quote, mi.documents[b.position].Quote)
+		})
+	} else {
+		slices.SortStableFunc(hits, func(a, b memoryHit) int {
+			return cmp.Compare(b.score, a.score)
+		})
+	}
+
+	return hits, nil
+}
+
+func matches(document indexer.Document, filters []Filter) bool {
+	for _, filter := range filters {
+		var keys []string
+
+		switch filter.Field {
+		case CharacterFilter:
+			keys = document.CharacterKeys
+		case ContextFilter:
+			keys = document.ContextKeys
+		case BookFilter:
+			keys = []string{strconv.Itoa(document.Book)}
+		}
+
+		if !slices.Contains(keys, filter.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareEpisodes orders quotes by book then episode, the ones without episode last.
+func compareEpisodes(a, b model.Quote) int {
+	if (a.Book == 0) != (b.Book == 0) {
+		if a.Book == 0 {
+			return 1
+		}
+
+		return -1
+	}
+
+	return cmp.Or(cmp.Compare(a.Book, b.Book), cmp.Compare(a.Episode, b.Episode))
+}
+
+func sanitizeWords(content string) ([]string, error) {
+	var output []string
+
+	for word := range strings.FieldsFuncSeq(content, isSeparator) {
+		sanitized, err := indexer.SanitizeName(word)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(sanitized) != 0 {
+			output = append(output, sanitized)
+		}
+	}
+
+	return output, nil
+}
+
+func isSeparator(r rune) bool {
+	if r == '\'' || r == '’' {
+		return false
+	}
+
+	return unicode.IsSpace(r) || unicode.IsPunct(r)
+}
+
+// matchScore mimics Meilisearch typo tolerance: one typo allowed from 5 characters, two from 9.
+func matchScore(term string, words []string) int {
+	allowedTypos := 0
+	if len(term) >= 9 {
+		allowedTypos = 2
+	} else if len(term) >= 5 {
+		allowedTypos = 1
+	}
+
+	var best int
+
+	for _, word := range words {
+		switch {
+		case word == term:
+			return 4
+
+		case strings.HasPrefix(word, term):
+			best = max(best, 3)
+
+		case allowedTypos != 0 && indexer.Levenshtein(term, word) <= allowedTypos:
+			best = max(best, 2)
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,298 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+)
+
+const (
+	memoryCollection = `[
+  {"id": "pas-faux", "value": "C'est pas faux.", "character": "Perceval", "context": "Livre II, 3 - Le Code de Chevalerie"},
+  {"id": "a-l-aise", "value": "Vous êtes à l'aise, là ?", "character": "Arthur", "context": "Livre I, 1 - Heat"},
+  {"id": "sloubi", "value": "Sloubi un, sloubi deux, sloubi trois", "character": "Perceval, Karadoc", "context": "Livre II, 1 - Le Sloubi"},
+  {"id": "chevalier", "value": "Un chevalier, ça ne recule jamais", "character": "Lancelot"}
+]`
+	memoryAliases = `{"pas-faux": ["0123456789abcdef"]}`
+)
+
+// newTestMemory indexes the test collection as the indexer loads it, so the documents have the keys the backend receives in production.
+func newTestMemory(t *testing.T, collection string) (Service, *Memory) {
+	t.Helper()
+
+	directory := t.TempDir()
+
+	for filename, content := range map[string]string{"test.json": collection, "test_aliases.json": memoryAliases} {
+		if err := os.WriteFile(filepath.Join(directory, filename), []byte(content), 0o600); err != nil {
+			t.Fatalf("write `%s`: %s", filename, err)
+		}
+	}
+
+	indexerService, err := indexer.New(context.Background(), &indexer.Config{Source: directory, EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("indexer: %s", err)
+	}
+
+	documents, err := indexerService.Load(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+
+	index, err := newMemoryIndex(documents)
+	if err != nil {
+		t.Fatalf("newMemoryIndex() = %s", err)
+	}
+
+	memory := &Memory{indexes: map[string]memoryIndex{"test": index}}
+
+	return Service{backend: memory, indexer: indexerService}, memory
+}
+
+func TestMatchScore(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"un", "chevalier", "sloubi", "faux"}
+
+	cases := map[string]struct {
+		term string
+		want int
+	}{
+		"exact": {
+			term: "faux",
+			want: 4,
+		},
+		"prefix": {
+			term: "chev",
+			want: 3,
+		},
+		"no typo under 5 characters": {
+			term: "faix",
+			want: 0,
+		},
+		"one typo from 5 characters": {
+			term: "sloubu",
+			want: 2,
+		},
+		"two typos under 9 characters": {
+			term: "slaubu",
+			want: 0,
+		},
+		"two typos from 9 characters": {
+			term: "chavaliex",
+			want: 2,
+		},
+		"three typos": {
+			term: "chavalixx",
+			want: 0,
+		},
+		"no match": {
+			term: "graal",
+			want: 0,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := matchScore(testCase.term, words); got != testCase.want {
+				t.Errorf("matchScore(`%s`) = %d, want %d", testCase.term, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestMemorySearch(t *testing.T) {
+	t.Parallel()
+
+	service, _ := newTestMemory(t, memoryCollection)
+
+	cases := map[string]struct {
+		query string
+		want  []string
+	}{
+		"by episode without query": {
+			want: []string{"a-l-aise", "sloubi", "pas-faux", "chevalier"},
+		},
+		"exact": {
+			query: "pas faux",
+			want:  []string{"pas-faux"},
+		},
+		"every term has to match": {
+			query: "pas graal",
+		},
+		"typo": {
+			query: "sloubu",
+			want:  []string{"sloubi"},
+		},
+		"accents": {
+			query: "etes a l'aise",
+			want:  []string{"a-l-aise"},
+		},
+		"character": {
+			query: "perso:Perceval",
+			want:  []string{"sloubi", "pas-faux"},
+		},
+		"one of the characters": {
+			query: "perso:karadoc",
+			want:  []string{"sloubi"},
+		},
+		"episode title": {
+			query: "episode:heat",
+			want:  []string{"a-l-aise"},
+		},
+		"episode with book": {
+			query: `episode:"Livre II"`,
+			want:  []string{"sloubi", "pas-faux"},
+		},
+		"episode with book and title": {
+			query: `contexte:"Livre II, 3 - Le Code de Chevalerie"`,
+			want:  []string{"pas-faux"},
+		},
+		"arabic book": {
+			query: "livre:2",
+			want:  []string{"sloubi", "pas-faux"},
+		},
+		"roman book": {
+			query: "livre:ii",
+			want:  []string{"sloubi", "pas-faux"},
+		},
+		"query and filters": {
+			query: "faux perso:Perceval livre:II",
+			want:  []string{"pas-faux"},
+		},
+		"filters exclude": {
+			query: "faux perso:arthur",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			text, filters := ParseQuery(testCase.query)
+
+			var got []string
+
+			for offset := 0; ; offset++ {
+				quote, total, err := service.Search(context.Background(), "test", text, filters, offset)
+				if errors.Is(err, ErrNotFound) {
+					break
+				}
+
+				if err != nil {
+					t.Fatalf("Search(%d) = %s", offset, err)
+				}
+
+				if total != len(testCase.want) {
+					t.Errorf("Search(%d) total = %d, want %d", offset, total, len(testCase.want))
+				}
+
+				got = append(got, quote.ID)
+			}
+
+			if !slices.Equal(got, testCase.want) {
+				t.Errorf("Search(`%s`) = %q, want %q", testCase.query, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestMemorySearchOffset(t *testing.T) {
+	t.Parallel()
+
+	_, memory := newTestMemory(t, memoryCollection)
+
+	if _, _, err := memory.Search(context.Background(), "test", "", nil, -1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Search(-1) = %v, want %s", err, ErrNotFound)
+	}
+
+	if _, _, err := memory.Search(context.Background(), "test", "", nil, 4); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Search(4) = %v, want %s", err, ErrNotFound)
+	}
+
+	if _, _, err := memory.Search(context.Background(), "oss117", "", nil, 0); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("Search(`oss117`) = %v, want %s", err, ErrIndexNotFound)
+	}
+
+	quotes, total, err := memory.SearchHits(context.Background(), "test", "", nil, 1, 2)
+	if err != nil {
+		t.Fatalf("SearchHits() = %s", err)
+	}
+
+	if total != 4 || len(quotes) != 2 || quotes[0].ID != "sloubi" || quotes[1].ID != "pas-faux" {
+		t.Errorf("SearchHits(1, 2) = %+v, %d, want `sloubi` and `pas-faux` of 4", quotes, total)
+	}
+}
+
+func TestMemoryGetByID(t *testing.T) {
+	t.Parallel()
+
+	_, memory := newTestMemory(t, memoryCollection)
+
+	cases := map[string]struct {
+		id      string
+		want    string
+		wantErr error
+	}{
+		"id": {
+			id:   "sloubi",
+			want: "sloubi",
+		},
+		"alias": {
+			id:   "0123456789abcdef",
+			want: "pas-faux",
+		},
+		"unknown": {
+			id:      "graal",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := memory.GetByID(context.Background(), "test", testCase.id)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Fatalf("GetByID(`%s`) = %v, want %v", testCase.id, err, testCase.wantErr)
+			}
+
+			if got.ID != testCase.want {
+				t.Errorf("GetByID(`%s`) = `%s`, want `%s`", testCase.id, got.ID, testCase.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRandom(t *testing.T) {
+	t.Parallel()
+
+	_, memory := newTestMemory(t, memoryCollection)
+
+	for range 20 {
+		got, err := memory.Random(context.Background(), "test", []string{"pas-faux", "a-l-aise", "chevalier"})
+		if err != nil {
+			t.Fatalf("Random() = %s", err)
+		}
+
+		if got.ID != "sloubi" {
+			t.Errorf("Random() = `%s`, want the only one not excluded", got.ID)
+		}
+	}
+
+	if _, err := memory.Random(context.Background(), "test", []string{"pas-faux", "a-l-aise", "sloubi", "chevalier"}); err != nil {
+		t.Errorf("Random() with everything excluded = %s, want a quote anyway", err)
+	}
+
+	_, empty := newTestMemory(t, "[]")
+
+	if _, err := empty.Random(context.Background(), "test", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Random() of an empty index = %v, want %s", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	filterPattern = regexp.MustCompile(`(?i)(\pL+):(?:"([^"]*)"|(\S+))`)
+
+	filterFields = map[string]string{
+		"perso":      CharacterFilter,
+		"personnage": CharacterFilter,
+		"episode":    ContextFilter,
+		"épisode":    ContextFilter,
+		"contexte":   ContextFilter,
+		"livre":      BookFilter,
+	}
+)
+
+// ParseQuery extracts the `key:value` or `key:"some value"` filters from the query and returns the remaining text.
+func ParseQuery(query string) (string, []Filter) {
+	var filters []Filter
+	var text strings.Builder
+
+	var last int
+
+	for _, match := range filterPattern.FindAllStringSubmatchIndex(query, -1) {
+		field, ok := filterFields[strings.ToLower(query[match[2]:match[3]])]
+		if !ok {
+			continue
+		}
+
+		var value string
+		if match[4] != -1 {
+			value = query[match[4]:match[5]]
+		} else {
+			value = query[match[6]:match[7]]
+		}
+
+		text.WriteString(query[last:match[0]])
+		last = match[1]
+
+		if len(strings.TrimSpace(value)) != 0 {
+			filters = append(filters, Filter{Field: field, Value: value})
+		}
+	}
+
+	text.WriteString(query[last:])
+
+	return strings.Join(strings.Fields(text.String()), " "), filters
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		query       string
+		wantText    string
+		wantFilters []Filter
+	}{
+		"text": {
+			query:    "  c'est   pas faux ",
+			wantText: "c'est pas faux",
+		},
+		"filter": {
+			query:       "faux perso:Perceval",
+			wantText:    "faux",
+			wantFilters: []Filter{{Field: CharacterFilter, Value: "Perceval"}},
+		},
+		"quoted filter": {
+			query:       `episode:"Livre II, 3" graal`,
+			wantText:    "graal",
+			wantFilters: []Filter{{Field: ContextFilter, Value: "Livre II, 3"}},
+		},
+		"aliases and case": {
+			query:       "PERSONNAGE:karadoc Épisode:heat contexte:heat livre:II",
+			wantFilters: []Filter{{Field: CharacterFilter, Value: "karadoc"}, {Field: ContextFilter, Value: "heat"}, {Field: ContextFilter, Value: "heat"}, {Field: BookFilter, Value: "II"}},
+		},
+		"unknown field kept as text": {
+			query:    "heure:midi graal",
+			wantText: "heure:midi graal",
+		},
+		"empty value dropped": {
+			query:    `perso:"" graal`,
+			wantText: "graal",
+		},
+		"url kept as text": {
+			query:    "https://kaamelott.fr",
+			wantText: "https://kaamelott.fr",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			gotText, gotFilters := ParseQuery(testCase.query)
+
+			if gotText != testCase.wantText {
+				t.Errorf("ParseQuery(`%s`) text = `%s`, want `%s`", testCase.query, gotText, testCase.wantText)
+			}
+
+			if !reflect.DeepEqual(gotFilters, testCase.wantFilters) {
+				t.Errorf("ParseQuery(`%s`) filters = %+v, want %+v", testCase.query, gotFilters, testCase.wantFilters)
+			}
+		})
+	}
+}
+
+func TestSearchInvalidFilters(t *testing.T) {
+	t.Parallel()
+
+	indexerService, err := indexer.New(context.Background(), &indexer.Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("indexer: %s", err)
+	}
+
+	service := Service{indexer: indexerService}
+
+	cases := map[string]struct {
+		filters     []Filter
+		wantErr     error
+		wantMessage string
+	}{
+		"malformed book": {
+			filters:     []Filter{{Field: BookFilter, Value: "IIII"}},
+			wantErr:     indexer.ErrUnknownBook,
+			wantMessage: "`IIII`",
+		},
+		"unknown field": {
+			filters:     []Filter{{Field: "heure", Value: "midi"}},
+			wantErr:     httpmodel.ErrInvalid,
+			wantMessage: "unknown filter `heure`",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := service.Search(context.Background(), "kaamelott", "", testCase.filters, 0)
+
+			if !errors.Is(err, httpmodel.ErrInvalid) || !errors.Is(err, testCase.wantErr) {
+				t.Fatalf("Search() = %v, want %s and %s", err, httpmodel.ErrInvalid, testCase.wantErr)
+			}
+
+			if !strings.Contains(err.Error(), testCase.wantMessage) {
+				t.Errorf("Search() = `%s`, want it to mention %s", err, testCase.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ViBiOh/kaamebott/pkg/version"
+)
+
+const (
+	reindexTimeout  = time.Minute * 5
+	defaultDuration = time.Second * 30
+)
+
+// IndexingError is returned while an index is being rebuilt.
+type IndexingError struct {
+	Since    time.Time
+	Duration time.Duration
+	Running  bool
+}
+
+func (ie IndexingError) Error() string {
+	return ErrIndexNotFound.Error()
+}
+
+func (ie IndexingError) Unwrap() error {
+	return ErrIndexNotFound
+}
+
+// Remaining estimates the time left before the index is available, based on the last indexing duration.
+func (ie IndexingError) Remaining() time.Duration {
+	return max(ie.Duration-time.Since(ie.Since), 0).Round(time.Second)
+}
+
+func (m Meilisearch) reindex(ctx context.Context, indexName string) error {
+	duration := m.lastDuration(ctx, indexName)
+
+	if since, ok := m.reindexing.Load(indexName); ok {
+		return IndexingError{Since: since.(time.Time), Duration: duration, Running: true}
+	}
+
+	if since, ok := m.runningElsewhere(ctx, indexName); ok {
+		return IndexingError{Since: since, Duration: duration, Running: true}
+	}
+
+	now := time.Now()
+
+	if since, loaded := m.reindexing.LoadOrStore(indexName, now); loaded {
+		return IndexingError{Since: since.(time.Time), Duration: duration, Running: true}
+	}
+
+	go func(ctx context.Context) {
+		defer m.reindexing.Delete(indexName)
+
+		if err := m.exclusiveIndex(ctx, indexName); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, fmt.Sprintf("fail to index `%s`", indexName), slog.Any("error", err))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return IndexingError{Since: now, Duration: duration}
+}
+
+func (m Meilisearch) exclusiveIndex(ctx context.Context, indexName string) error {
+	if !m.redis.Enabled() {
+		return m.timedIndex(ctx, indexName)
+	}
+
+	acquired, err := m.redis.Exclusive(ctx, version.Redis("reindex:lock:"+indexName), reindexTimeout, func(ctx context.Context) error {
+		return m.timedIndex(ctx, indexName)
+	})
+
+	if !acquired && err == nil {
+		slog.LogAttrs(ctx, slog.LevelInfo, "index already being rebuilt by another instance", slog.String("index", indexName))
+	}
+
+	return err
+}
+
+func (m Meilisearch) timedIndex(ctx context.Context, indexName string) error {
+	start := time.Now()
+	statusKey := version.Redis("reindex:since:" + indexName)
+
+	if err := m.redis.Store(ctx, statusKey, strconv.FormatInt(start.Unix(), 10), reindexTimeout); err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "store reindex status", slog.String("index", indexName), slog.Any("error", err))
+	}
+
+	defer func() {
+		if err := m.redis.Delete(ctx, statusKey); err != nil {
+			slog.LogAttrs(ctx, slog.LevelWarn, "delete reindex status", slog.String("index", indexName), slog.Any("error", err))
+		}
+	}()
+
+	if err := m.Index(ctx, indexName); err != nil {
+		return err
+	}
+
+	if err := m.redis.Store(ctx, version.Redis("reindex:duration:"+indexName), time.Since(start).String(), 0); err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "store reindex duration", slog.String("index", indexName), slog.Any("error", err))
+	}
+
+	return nil
+}
+
+func (m Meilisearch) runningElsewhere(ctx context.Context, indexName string) (time.Time, bool) {
+	content, err := m.redis.Load(ctx, version.Redis("reindex:since:"+indexName))
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "load reindex status", slog.String("index", indexName), slog.Any("error", err))
+		return time.Time{}, false
+	}
+
+	if len(content) == 0 {
+		return time.Time{}, false
+	}
+
+	timestamp, err := strconv.ParseInt(string(content), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(timestamp, 0), true
+}
+
+func (m Meilisearch) lastDuration(ctx context.Context, indexName string) time.Duration {
+	content, err := m.redis.Load(ctx, version.Redis("reindex:duration:"+indexName))
+	if err != nil || len(content) == 0 {
+		return defaultDuration
+	}
+
+	duration, err := time.ParseDuration(string(content))
+	if err != nil {
+		return defaultDuration
+	}
+
+	return duration
+}
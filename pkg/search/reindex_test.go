@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/version"
+)
+
+// statusRedis keeps the reindex status of other instances and counts the ones stored.
+type statusRedis struct {
+	redis.Noop
+	values map[string]string
+	stored map[string]int
+	mutex  sync.Mutex
+}
+
+func newStatusRedis(values map[string]string) *statusRedis {
+	return &statusRedis{values: values, stored: make(map[string]int)}
+}
+
+func (sr *statusRedis) Load(_ context.Context, key string) ([]byte, error) {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	return []byte(sr.values[key]), nil
+}
+
+func (sr *statusRedis) Store(_ context.Context, key string, value any, _ time.Duration) error {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	sr.stored[key]++
+
+	return nil
+}
+
+func (sr *statusRedis) storedCount(key string) int {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	return sr.stored[key]
+}
+
+// newTestMeilisearch serves an unavailable Meilisearch, answering once `release` is closed.
+func newTestMeilisearch(t *testing.T, release <-chan struct{}, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message": "Unavailable.", "code": "bad_request", "type": "invalid_request", "link": ""}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestReindex(t *testing.T, url string, redisClient redis.Client) Meilisearch {
+	t.Helper()
+
+	indexerService, err := indexer.New(context.Background(), &indexer.Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("indexer: %s", err)
+	}
+
+	return NewMeilisearch(url, indexerService, redisClient)
+}
+
+func isReindexing(meilisearch Meilisearch, indexName string) bool {
+	_, ok := meilisearch.reindexing.Load(indexName)
+	return ok
+}
+
+func TestReindexSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var requests atomic.Int32
+
+	statuses := newStatusRedis(nil)
+	meilisearch := newTestReindex(t, newTestMeilisearch(t, release, &requests).URL, statuses)
+
+	const concurrency = 20
+
+	errs := make(chan error, concurrency)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			errs <- meilisearch.reindex(context.Background(), "kaamelott")
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var started int
+	for err := range errs {
+		indexingErr, ok := errors.AsType[IndexingError](err)
+		if !ok || !errors.Is(err, ErrIndexNotFound) {
+			t.Fatalf("reindex() = %v, want an IndexingError", err)
+		}
+
+		if !indexingErr.Running {
+			started++
+		}
+
+		if indexingErr.Duration != defaultDuration {
+			t.Errorf("reindex().Duration = %s, want the default one", indexingErr.Duration)
+		}
+	}
+
+	if started != 1 {
+		t.Errorf("reindex() started %d times, want once", started)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second * 5)
+	for isReindexing(meilisearch, "kaamelott") {
+		if time.Now().After(deadline) {
+			t.Fatal("reindex didn't end")
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if got := statuses.storedCount(version.Redis("reindex:since:kaamelott")); got != 1 {
+		t.Errorf("reindex status stored %d times, want once", got)
+	}
+
+	if got := requests.Load(); got == 0 {
+		t.Error("reindex didn't reach Meilisearch")
+	}
+
+	if err := meilisearch.reindex(context.Background(), "kaamelott"); err == nil || err.(IndexingError).Running {
+		t.Errorf("reindex() once the previous one ended = %v, want a new one started", err)
+	}
+}
+
+func TestReindexRunningElsewhere(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	close(release)
+
+	var requests atomic.Int32
+
+	since := time.Now().Add(-time.Second * 20).Truncate(time.Second)
+
+	statuses := newStatusRedis(map[string]string{
+		version.Redis("reindex:since:oss117"):    strconv.FormatInt(since.Unix(), 10),
+		version.Redis("reindex:duration:oss117"): "1m0s",
+	})
+
+	meilisearch := newTestReindex(t, newTestMeilisearch(t, release, &requests).URL, statuses)
+
+	indexingErr, ok := errors.AsType[IndexingError](meilisearch.reindex(context.Background(), "oss117"))
+	if !ok {
+		t.Fatal("reindex() didn't return an IndexingError")
+	}
+
+	if !indexingErr.Running || !indexingErr.Since.Equal(since) || indexingErr.Duration != time.Minute {
+		t.Errorf("reindex() = %+v, want the one running elsewhere since %s, for a minute", indexingErr, since)
+	}
+
+	if isReindexing(meilisearch, "oss117") || requests.Load() != 0 {
+		t.Error("reindex() started while running elsewhere")
+	}
+}
+
+func TestIndexingErrorRemaining(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		since    time.Duration
+		duration time.Duration
+		want     time.Duration
+	}{
+		"running": {
+			since:    time.Second * 20,
+			duration: time.Second * 30,
+			want:     time.Second * 10,
+		},
+		"overdue": {
+			since:    time.Minute,
+			duration: time.Second * 30,
+			want:     0,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			indexingErr := IndexingError{Since: time.Now().Add(-testCase.since), Duration: testCase.duration}
+
+			if got := indexingErr.Remaining(); got != testCase.want {
+				t.Errorf("Remaining() = %s, want %s", got, testCase.want)
+			}
+		})
+	}
+}
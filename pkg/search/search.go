@@ -6,28 +6,51 @@ import (
 	"flag"
 	"fmt"
 	"html/template"
-	"log/slog"
-	"math/rand/v2"
-	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/ViBiOh/flags"
+	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/kaamebott/pkg/card"
 	"github.com/ViBiOh/kaamebott/pkg/indexer"
 	"github.com/ViBiOh/kaamebott/pkg/model"
-	"github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	CharacterFilter = "characterKeys"
+	ContextFilter   = "contextKeys"
+	BookFilter      = "book"
+
+	aliasFilter = "aliases"
 )
 
 var (
 	ErrNotFound      = errors.New("no result found")
 	ErrIndexNotFound = errors.New("index not found")
-	FuncMap          = template.FuncMap{}
+	FuncMap          = template.FuncMap{"quotePath": QuotePath}
 )
 
+type Backend interface {
+	HasIndex(ctx context.Context, indexName string) bool
+	GetByID(ctx context.Context, indexName, id string) (model.Quote, error)
+	Search(ctx context.Context, indexName, query string, filters []Filter, offset int) (model.Quote, int, error)
+	SearchHits(ctx context.Context, indexName, query string, filters []Filter, offset, limit int) ([]model.Quote, int, error)
+	Random(ctx context.Context, indexName string, excluded []string) (model.Quote, error)
+	Index(ctx context.Context, indexName string) error
+}
+
+// Filter restricts a search to the documents having Value in their Field keys.
+type Filter struct {
+	Field string
+	Value string
+}
+
 type Service struct {
-	random   *rand.Rand
 	renderer *renderer.Service
-	search   meilisearch.ServiceManager
+	backend  Backend
+	indexer  indexer.Service
+	card     card.Service
 }
 
 type Config struct {
@@ -37,78 +60,97 @@ type Config struct {
 func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
 	var config Config
 
-	flags.New("URL", "Meilisearch URL").Prefix(prefix).DocPrefix("search").StringVar(fs, &config.URL, "http://meilisearch:7700", overrides)
+	flags.New("URL", "Meilisearch URL (blank for in-memory search)").Prefix(prefix).DocPrefix("search").StringVar(fs, &config.URL, "http://meilisearch:7700", overrides)
 
 	return &config
 }
 
-func New(config *Config, rendererService *renderer.Service) Service {
-	return Service{
-		search:   meilisearch.New(config.URL),
-		random:   rand.New(rand.NewPCG(uint64(time.Now().Unix()), uint64(time.Now().UnixMicro()))),
+func New(ctx context.Context, config *Config, indexerService indexer.Service, redisClient redis.Client, rendererService *renderer.Service, cardService card.Service) (Service, error) {
+	service := Service{
 		renderer: rendererService,
+		indexer:  indexerService,
+		card:     cardService,
 	}
-}
 
-func (s Service) HasIndex(ctx context.Context, indexName string) bool {
-	index, err := s.search.GetIndex(indexName)
-	if err != nil {
-		slog.LogAttrs(ctx, slog.LevelError, fmt.Sprintf("check if index `%s` exists", indexName), slog.Any("error", err))
+	if len(config.URL) != 0 {
+		service.backend = NewMeilisearch(config.URL, indexerService, redisClient)
+		return service, nil
 	}
 
-	return index != nil
-}
-
-func (s Service) GetByID(ctx context.Context, indexName, id string) (model.Quote, error) {
-	index, err := s.search.GetIndex(indexName)
+	backend, err := NewMemory(ctx, indexerService)
 	if err != nil {
-		return model.Quote{}, err
+		return service, fmt.Errorf("memory: %w", err)
 	}
 
-	var output model.Quote
+	service.backend = backend
 
-	return output, index.GetDocument(id, &meilisearch.DocumentQuery{}, &output)
+	return service, nil
 }
 
-func (s Service) Search(ctx context.Context, indexName, query string, offset int) (model.Quote, error) {
-	index, err := s.search.GetIndex(indexName)
-	if err != nil {
-		if meiliError, ok := errors.AsType[*meilisearch.Error](err); ok && meiliError.StatusCode == http.StatusNotFound {
-			go func(ctx context.Context) {
-				if indexErr := indexer.Index(ctx, s.search, indexName); indexErr != nil {
-					slog.LogAttrs(ctx, slog.LevelError, fmt.Sprintf("fail to index `%s`", indexName), slog.Any("error", indexErr))
-				}
-			}(context.WithoutCancel(ctx))
-
-			return model.Quote{}, ErrIndexNotFound
-		}
+func (s Service) HasIndex(ctx context.Context, indexName string) bool {
+	return s.backend.HasIndex(ctx, indexName)
+}
 
-		return model.Quote{}, fmt.Errorf("get index: %w", err)
-	}
+func (s Service) GetByID(ctx context.Context, indexName, id string) (model.Quote, error) {
+	return s.backend.GetByID(ctx, indexName, id)
+}
 
-	results, err := index.Search(query, &meilisearch.SearchRequest{Limit: 1, Offset: int64(offset)})
+// Search returns the quote at `offset` of the ones matching the query, and their estimated count.
+func (s Service) Search(ctx context.Context, indexName, query string, filters []Filter, offset int) (model.Quote, int, error) {
+	filters, err := s.normalizeFilters(indexName, filters)
 	if err != nil {
-		return model.Quote{}, err
+		return model.Quote{}, 0, fmt.Errorf("normalize filters: %w: %w", httpmodel.ErrInvalid, err)
 	}
 
-	if len(results.Hits) == 0 {
-		return model.Quote{}, ErrNotFound
-	}
-
-	var output model.Quote
+	return s.backend.Search(ctx, indexName, query, filters, offset)
+}
 
-	var content map[string]any
-	if err := results.Hits[0].DecodeInto(&content); err != nil {
-		return model.Quote{}, err
+// SearchHits returns the `limit` best quotes matching the query from `offset`, and the estimated count of matching ones.
+func (s Service) SearchHits(ctx context.Context, indexName, query string, filters []Filter, offset, limit int) ([]model.Quote, int, error) {
+	filters, err := s.normalizeFilters(indexName, filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("normalize filters: %w: %w", httpmodel.ErrInvalid, err)
 	}
 
-	return output, index.GetDocument(content["id"].(string), &meilisearch.DocumentQuery{}, &output)
+	return s.backend.SearchHits(ctx, indexName, query, filters, offset, limit)
 }
 
-func (s Service) Random(ctx context.Context, indexName string) (model.Quote, error) {
-	return s.Search(ctx, indexName, "", 0)
+func (s Service) Random(ctx context.Context, indexName string, excluded []string) (model.Quote, error) {
+	return s.backend.Random(ctx, indexName, excluded)
 }
 
-func (s Service) TemplateFunc(w http.ResponseWriter, r *http.Request) (renderer.Page, error) {
-	return renderer.NewPage("public", http.StatusOK, nil), nil
+// Characters lists the characters of the index, by their canonical name.
+func (s Service) Characters(indexName string) []indexer.Character {
+	return s.indexer.Characters(indexName)
+}
+
+func (s Service) normalizeFilters(indexName string, filters []Filter) ([]Filter, error) {
+	output := make([]Filter, 0, len(filters))
+
+	for _, filter := range filters {
+		var value string
+		var err error
+
+		switch filter.Field {
+		case CharacterFilter:
+			value, err = s.indexer.CharacterKey(indexName, filter.Value)
+		case ContextFilter:
+			value, err = indexer.ContextKey(filter.Value)
+		case BookFilter:
+			var book int
+			if book, err = indexer.BookNumber(filter.Value); err == nil {
+				value = strconv.Itoa(book)
+			}
+		default:
+			return nil, fmt.Errorf("unknown filter `%s`", filter.Field)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("sanitize `%s`: %w", filter.Value, err)
+		}
+
+		output = append(output, Filter{Field: filter.Field, Value: value})
+	}
+
+	return output, nil
 }
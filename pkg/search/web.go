@@ -0,0 +1,246 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	httpmodel "github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/kaamebott/pkg/card"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/model"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+const (
+	universeParam  = "universe"
+	queryParam     = "recherche"
+	characterParam = "perso"
+	offsetParam    = "offset"
+	versionParam   = "v"
+	quotesSegment  = "quotes"
+	cardExtension  = ".png"
+
+	webPageSize = 10
+)
+
+// Paths of the website pages, in the syntax of the OpenAPI specification.
+const (
+	SearchPattern = "/"
+	QuotePattern  = "/{universe}/" + quotesSegment + "/{id}"
+	CardPattern   = QuotePattern + cardExtension
+)
+
+type webUniverse struct {
+	universe.Universe
+	Characters []indexer.Character
+}
+
+// webSeo overrides the default Open Graph metadata of the page.
+type webSeo struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+}
+
+type webSearch struct {
+	Universe  universe.Universe
+	Query     string
+	Character string
+	Previous  string
+	Next      string
+	Quotes    []model.Quote
+	Total     int
+	From      int
+	To        int
+}
+
+// QuotePath is the path of the permalink page of a quote.
+func QuotePath(indexName, id string) string {
+	return fmt.Sprintf("/%s/%s/%s", url.PathEscape(indexName), quotesSegment, url.PathEscape(id))
+}
+
+// CardPath is the path of the image card of a quote, with its version so that caches don't serve a stale card.
+func CardPath(item universe.Universe, quote model.Quote) string {
+	return QuotePath(item.Name, quote.ID) + cardExtension + "?" + versionParam + "=" + card.Version(item, quote)
+}
+
+// TemplateFunc renders the permalink page of a quote, or the public page with the results of the search described by its query string, e.g. `/?universe=kaamelott&recherche=graal`.
+func (s Service) TemplateFunc(w http.ResponseWriter, r *http.Request) (renderer.Page, error) {
+	if parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/"); len(parts) == 3 && parts[1] == quotesSegment {
+		if id, ok := strings.CutSuffix(parts[2], cardExtension); ok {
+			return s.quoteCard(w, r, parts[0], id)
+		}
+
+		return s.quotePage(r, parts[0], parts[2])
+	}
+
+	universes := universe.All()
+
+	items := make([]webUniverse, len(universes))
+	for i, item := range universes {
+		items[i] = webUniverse{Universe: item, Characters: s.Characters(item.Name)}
+	}
+
+	content := map[string]any{
+		"Universes": items,
+	}
+
+	params := r.URL.Query()
+
+	name := params.Get(universeParam)
+	if len(name) == 0 {
+		return renderer.NewPage("public", http.StatusOK, content), nil
+	}
+
+	item, ok := universe.Get(name)
+	if !ok {
+		return renderer.NewPage("public", http.StatusNotFound, content), httpmodel.WrapNotFound(fmt.Errorf("unknown universe `%s`", name))
+	}
+
+	offset, err := strconv.Atoi(params.Get(offsetParam))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	result := webSearch{
+		Universe:  item,
+		Query:     strings.TrimSpace(params.Get(queryParam)),
+		Character: strings.TrimSpace(params.Get(characterParam)),
+	}
+
+	text, filters := ParseQuery(result.Query)
+	if len(result.Character) != 0 {
+		filters = append(filters, Filter{Field: CharacterFilter, Value: result.Character})
+	}
+
+	result.Quotes, result.Total, err = s.SearchHits(r.Context(), item.Name, text, filters, offset, webPageSize)
+	if err != nil {
+		if errors.Is(err, ErrIndexNotFound) {
+			err = httpmodel.WrapNotFound(err)
+		}
+
+		return renderer.NewPage("public", http.StatusOK, content), err
+	}
+
+	if len(result.Quotes) != 0 {
+		result.From = offset + 1
+		result.To = offset + len(result.Quotes)
+	}
+
+	if offset > 0 {
+		result.Previous = result.pageURL(max(offset-webPageSize, 0))
+	}
+
+	if offset+len(result.Quotes) < result.Total {
+		result.Next = result.pageURL(offset + webPageSize)
+	}
+
+	content["Search"] = result
+
+	return renderer.NewPage("public", http.StatusOK, content), nil
+}
+
+func (ws webSearch) pageURL(offset int) string {
+	params := url.Values{}
+	params.Set(universeParam, ws.Universe.Name)
+
+	if len(ws.Query) != 0 {
+		params.Set(queryParam, ws.Query)
+	}
+
+	if len(ws.Character) != 0 {
+		params.Set(characterParam, ws.Character)
+	}
+
+	if offset != 0 {
+		params.Set(offsetParam, strconv.Itoa(offset))
+	}
+
+	return "?" + params.Encode()
+}
+
+func (s Service) quotePage(r *http.Request, indexName, id string) (renderer.Page, error) {
+	item, quote, err := s.getQuote(r, indexName, id)
+	if err != nil {
+		return renderer.NewPage("quote", http.StatusOK, nil), err
+	}
+
+	title := item.Title
+	if item.ShowCharacter && len(quote.Character) != 0 {
+		title = fmt.Sprintf("%s - %s", quote.Character, item.Title)
+	}
+
+	return renderer.NewPage("quote", http.StatusOK, map[string]any{
+		"Quote":    quote,
+		"Universe": item,
+		"Search":   webSearch{Universe: item}.pageURL(0),
+		"Seo": webSeo{
+			Title:       title,
+			Description: quote.Value,
+			URL:         s.renderer.PublicURL(QuotePath(item.Name, quote.ID)),
+			Image:       s.quoteImage(item, quote),
+		},
+	}), nil
+}
+
+// quoteCard writes the image card of a quote, without rendering a template.
+func (s Service) quoteCard(w http.ResponseWriter, r *http.Request, indexName, id string) (renderer.Page, error) {
+	item, quote, err := s.getQuote(r, indexName, id)
+	if err != nil {
+		return renderer.NewPage("quote", http.StatusOK, nil), err
+	}
+
+	content, err := s.card.Get(r.Context(), item, quote)
+	if err != nil {
+		return renderer.NewPage("quote", http.StatusOK, nil), fmt.Errorf("card: %w", err)
+	}
+
+	cacheControl := "no-cache"
+	if r.URL.Query().Get(versionParam) == card.Version(item, quote) {
+		cacheControl = "public, max-age=86400, immutable"
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(content); err != nil {
+		slog.LogAttrs(r.Context(), slog.LevelWarn, "write card", slog.String("id", quote.ID), slog.Any("error", err))
+	}
+
+	return renderer.Page{}, nil
+}
+
+func (s Service) getQuote(r *http.Request, indexName, id string) (universe.Universe, model.Quote, error) {
+	item, ok := universe.Get(indexName)
+	if !ok {
+		return item, model.Quote{}, httpmodel.WrapNotFound(fmt.Errorf("unknown universe `%s`", indexName))
+	}
+
+	quote, err := s.GetByID(r.Context(), item.Name, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrIndexNotFound) {
+			err = httpmodel.WrapNotFound(err)
+		}
+
+		return item, quote, err
+	}
+
+	return item, quote, nil
+}
+
+// quoteImage is the gif of the quote, or its generated image card.
+func (s Service) quoteImage(item universe.Universe, quote model.Quote) string {
+	if len(quote.Image) != 0 {
+		return quote.Image
+	}
+
+	return s.renderer.PublicURL(CardPath(item, quote))
+}
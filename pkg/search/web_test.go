@@ -0,0 +1,243 @@
+package search
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/kaamebott/pkg/card"
+	"github.com/ViBiOh/kaamebott/pkg/indexer"
+	"github.com/ViBiOh/kaamebott/pkg/universe"
+)
+
+// newTestWeb serves the website with the templates of the binary, searching in memory or with the given Meilisearch.
+func newTestWeb(t *testing.T, searchURL string) (Service, http.Handler) {
+	t.Helper()
+
+	content := os.DirFS("../../cmd/kaamebott")
+
+	rendererService, err := renderer.New(context.Background(), &renderer.Config{PublicURL: "https://kaamebott.vibioh.fr", Title: "Kaamebott", Extension: "tmpl"}, content, FuncMap, nil, nil)
+	if err != nil {
+		t.Fatalf("renderer: %s", err)
+	}
+
+	static, err := fs.Sub(content, "static")
+	if err != nil {
+		t.Fatalf("static: %s", err)
+	}
+
+	cardService, err := card.New(redis.Noop{}, static, nil)
+	if err != nil {
+		t.Fatalf("card: %s", err)
+	}
+
+	indexerService, err := indexer.New(context.Background(), &indexer.Config{EnrichmentThreshold: 0.85})
+	if err != nil {
+		t.Fatalf("indexer: %s", err)
+	}
+
+	service, err := New(context.Background(), &Config{URL: searchURL}, indexerService, redis.Noop{}, rendererService, cardService)
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+
+	return service, rendererService.NewServeMux(service.TemplateFunc)
+}
+
+type webCase struct {
+	path     string
+	want     int
+	headers  map[string]string
+	contains []string
+	excludes []string
+}
+
+func testWeb(t *testing.T, handler http.Handler, cases map[string]webCase) {
+	t.Helper()
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+			if got := writer.Code; got != testCase.want {
+				t.Errorf("GET %s = %d, want %d", testCase.path, got, testCase.want)
+			}
+
+			for name, value := range testCase.headers {
+				if got := writer.Header().Get(name); got != value {
+					t.Errorf("GET %s header %s = `%s`, want `%s`", testCase.path, name, got, value)
+				}
+			}
+
+			body := writer.Body.String()
+
+			for _, content := range testCase.contains {
+				if !strings.Contains(body, content) {
+					t.Errorf("GET %s doesn't contain `%s`", testCase.path, content)
+				}
+			}
+
+			for _, content := range testCase.excludes {
+				if strings.Contains(body, content) {
+					t.Errorf("GET %s contains `%s`", testCase.path, content)
+				}
+			}
+		})
+	}
+}
+
+func TestWebSearch(t *testing.T) {
+	t.Parallel()
+
+	_, handler := newTestWeb(t, "")
+
+	testWeb(t, handler, map[string]webCase{
+		"home": {
+			path:     "/",
+			want:     http.StatusOK,
+			contains: []string{`name="universe" value="kaamelott"`, `name="universe" value="oss117"`},
+			excludes: []string{"Suivantes"},
+		},
+		"results": {
+			path:     "/?universe=kaamelott&recherche=pas+faux+perso:perceval",
+			want:     http.StatusOK,
+			contains: []string{"C&#39;est pas faux"},
+		},
+		"first page": {
+			path:     "/?universe=kaamelott&perso=perceval",
+			want:     http.StatusOK,
+			contains: []string{"citations 1 à 10 sur", "offset=10", "Suivantes"},
+			excludes: []string{"Précédentes"},
+		},
+		"next page": {
+			path:     "/?universe=kaamelott&perso=perceval&offset=10",
+			want:     http.StatusOK,
+			contains: []string{"citations 11 à 20 sur", "Précédentes", "Suivantes"},
+		},
+		"negative offset": {
+			path:     "/?universe=kaamelott&perso=perceval&offset=-10",
+			want:     http.StatusOK,
+			contains: []string{"citations 1 à 10 sur"},
+			excludes: []string{"Précédentes"},
+		},
+		"nothing found": {
+			path:     "/?universe=kaamelott&recherche=zzzzzzzzzz",
+			want:     http.StatusOK,
+			excludes: []string{"Suivantes", "Précédentes"},
+		},
+		"unknown universe": {
+			path: "/?universe=astier",
+			want: http.StatusNotFound,
+		},
+		"malformed book": {
+			path: "/?universe=kaamelott&recherche=livre:IIII",
+			want: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestWebSearchMissingIndex(t *testing.T) {
+	t.Parallel()
+
+	meilisearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Index not found.", "code": "index_not_found", "type": "invalid_request", "link": ""}`))
+	}))
+	t.Cleanup(meilisearch.Close)
+
+	_, handler := newTestWeb(t, meilisearch.URL)
+
+	testWeb(t, handler, map[string]webCase{
+		"search": {
+			path: "/?universe=kaamelott&recherche=graal",
+			want: http.StatusNotFound,
+		},
+		"permalink": {
+			path: "/kaamelott/quotes/cest_pas_faux2",
+			want: http.StatusNotFound,
+		},
+		"card": {
+			path: "/kaamelott/quotes/cest_pas_faux2.png",
+			want: http.StatusNotFound,
+		},
+	})
+}
+
+func TestWebQuote(t *testing.T) {
+	t.Parallel()
+
+	service, handler := newTestWeb(t, "")
+
+	kaamelott, _ := universe.Get("kaamelott")
+
+	quote, err := service.GetByID(context.Background(), kaamelott.Name, "cest_pas_faux2")
+	if err != nil {
+		t.Fatalf("GetByID() = %s", err)
+	}
+
+	image := quote.Image
+	if len(image) == 0 {
+		image = "https://kaamebott.vibioh.fr" + CardPath(kaamelott, quote)
+	}
+
+	former, err := service.GetByID(context.Background(), kaamelott.Name, "03762b7e3d0047a0")
+	if err != nil {
+		t.Fatalf("GetByID() = %s", err)
+	}
+
+	testWeb(t, handler, map[string]webCase{
+		"permalink": {
+			path: "/kaamelott/quotes/cest_pas_faux2",
+			want: http.StatusOK,
+			contains: []string{
+				`<meta property="og:title" content="Perceval - Kaamelott" />`,
+				`<meta property="og:description" content="Ouais c&#39;est pas faux" />`,
+				`<meta property="og:url" content="https://kaamebott.vibioh.fr/kaamelott/quotes/cest_pas_faux2" />`,
+				`<meta property="og:image" content="` + image + `" />`,
+				`href="/?universe=kaamelott"`,
+			},
+		},
+		"former id": {
+			path:     "/kaamelott/quotes/03762b7e3d0047a0",
+			want:     http.StatusOK,
+			contains: []string{`<meta property="og:url" content="https://kaamebott.vibioh.fr/kaamelott/quotes/` + former.ID + `" />`},
+		},
+		"image": {
+			path:     "/abitbol/quotes/b62dc9d8",
+			want:     http.StatusOK,
+			contains: []string{`<meta property="og:title" content="La Classe américaine" />`, `<meta property="og:image" content="https://george-abitbol.fr/doc/thumbs/171.jpg" />`},
+		},
+		"unknown quote": {
+			path: "/kaamelott/quotes/graal",
+			want: http.StatusNotFound,
+		},
+		"unknown universe": {
+			path: "/astier/quotes/cest_pas_faux2",
+			want: http.StatusNotFound,
+		},
+		"card": {
+			path:    "/kaamelott/quotes/cest_pas_faux2.png",
+			want:    http.StatusOK,
+			headers: map[string]string{"Content-Type": "image/png", "Cache-Control": "no-cache"},
+		},
+		"versioned card": {
+			path:    CardPath(kaamelott, quote),
+			want:    http.StatusOK,
+			headers: map[string]string{"Content-Type": "image/png", "Cache-Control": "public, max-age=86400, immutable"},
+		},
+		"unknown card": {
+			path: "/kaamelott/quotes/graal.png",
+			want: http.StatusNotFound,
+		},
+	})
+}
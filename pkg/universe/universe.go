@@ -0,0 +1,76 @@
+package universe
+
+import "slices"
+
+// Universe describes a quote collection: its command, its collection files `indexes/<name>.json` (with the optional `<name>_next.json` enrichment) and how its quotes are rendered.
+type Universe struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	// Thumbnail is the path of the default image on the website, or an absolute URL, if any.
+	Thumbnail string `json:"thumbnail"`
+
+	// ShowCharacter displays who said the quote.
+	ShowCharacter bool `json:"showCharacter"`
+
+	// LinkContext renders the context as a link to the quote URL.
+	LinkContext bool `json:"linkContext"`
+
+	// ImageAsThumbnail renders the quote image as a thumbnail instead of a full-size image.
+	ImageAsThumbnail bool `json:"imageAsThumbnail"`
+
+	// Books enables the filter on the book of quotes whose context is like `Livre I, 1 - Title`.
+	Books bool `json:"books"`
+}
+
+var universes = []Universe{
+	{
+		Name:          "kaamelott",
+		Title:         "Kaamelott",
+		Description:   "Get a Kaamelott quote",
+		Thumbnail:     "/images/kaamelott.png",
+		ShowCharacter: true,
+		LinkContext:   true,
+		Books:         true,
+	},
+	{
+		Name:          "oss117",
+		Title:         "OSS 117",
+		Description:   "Get an OSS 117 quote",
+		Thumbnail:     "/images/oss117.png",
+		ShowCharacter: true,
+	},
+	{
+		Name:             "abitbol",
+		Title:            "La Classe américaine",
+		Description:      "Get an Abitbol quote",
+		LinkContext:      true,
+		ImageAsThumbnail: true,
+	},
+}
+
+// Register adds the given universes, replacing the ones with the same name. It has to be called before serving requests.
+func Register(items ...Universe) {
+	for _, item := range items {
+		if index := slices.IndexFunc(universes, func(existing Universe) bool { return existing.Name == item.Name }); index != -1 {
+			universes[index] = item
+		} else {
+			universes = append(universes, item)
+		}
+	}
+}
+
+func All() []Universe {
+	return slices.Clone(universes)
+}
+
+func Get(name string) (Universe, bool) {
+	for _, universe := range universes {
+		if universe.Name == name {
+			return universe, true
+		}
+	}
+
+	return Universe{}, false
+}